@@ -1,21 +1,107 @@
 package bson
 
 import (
+	"errors"
 	"github.com/stretchr/codecs/constants"
-	"gopkg.in/mgo.v2/bson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/mgocompat"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+	"strconv"
+)
+
+const (
+	// OptionArrayConvention is the option key used to choose how top-level arrays and
+	// slices are encoded by Marshal.  The value must be one of the ArrayConvention
+	// constants, and overrides the codec's ArrayConvention field.
+	OptionArrayConvention string = "options.bson.arrays"
+
+	// ArrayConventionDocument encodes top-level arrays as a BSON array document, where
+	// the keys are the indexes of the items ("0", "1", ...).  This is the default.
+	ArrayConventionDocument string = "document"
+
+	// ArrayConventionWrap encodes top-level arrays as a document with a single key
+	// (ArrayWrapperKey) containing the array, such as {"items": [...]}.
+	ArrayConventionWrap string = "wrap"
+)
+
+// ArrayWrapperKey is the key that holds the items when the ArrayConventionWrap
+// convention is used.
+var ArrayWrapperKey string = "items"
+
+// ErrorUnknownArrayConvention is the error for when an array convention other than
+// ArrayConventionDocument or ArrayConventionWrap is specified.
+var ErrorUnknownArrayConvention = errors.New("codecs: bson: unknown array convention")
+
+var (
+	typeD   = reflect.TypeOf(primitive.D{})
+	typeRaw = reflect.TypeOf(bson.Raw{})
 )
 
 // BsonCodec converts objects to and from BSON.
-type BsonCodec struct{}
+//
+// Values are encoded and decoded using the mgo compatible registry from the
+// MongoDB Go driver, so documents have the same wire format as they did when
+// this codec used mgo.  When decoding into interface{} values, arrays decode
+// as []interface{} and datetimes as time.Time.
+//
+// BSON documents cannot be arrays, so top-level arrays and slices (such as the
+// []interface{} produced by codecs.PublicData for collections) are encoded using
+// an array convention.  Unmarshalling into a slice accepts either convention.
+// Unmarshalling into an interface{} produces a []interface{} if the document
+// matches the codec's array convention.
+type BsonCodec struct {
+
+	// ArrayConvention is the convention used for top-level arrays.  If empty,
+	// ArrayConventionDocument is used.
+	ArrayConvention string
+}
 
 // Marshal converts an object to BSON.
 func (b *BsonCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
-	return bson.Marshal(object)
+
+	if !isArray(reflect.ValueOf(object)) {
+		return bson.MarshalWithRegistry(mgocompat.Registry, object)
+	}
+
+	convention := b.arrayConvention()
+	if optionConvention, ok := options[OptionArrayConvention].(string); ok {
+		convention = optionConvention
+	}
+
+	switch convention {
+	case ArrayConventionDocument:
+
+		items := reflect.ValueOf(object)
+		doc := make(primitive.D, items.Len())
+		for index := range doc {
+			doc[index] = primitive.E{Key: strconv.Itoa(index), Value: items.Index(index).Interface()}
+		}
+
+		return bson.MarshalWithRegistry(mgocompat.Registry, doc)
+
+	case ArrayConventionWrap:
+		return bson.MarshalWithRegistry(mgocompat.Registry, primitive.D{{Key: ArrayWrapperKey, Value: object}})
+	}
+
+	return nil, ErrorUnknownArrayConvention
 }
 
-// Unmarshal converts JSON into an object.
+// Unmarshal converts BSON into an object.
 func (b *BsonCodec) Unmarshal(data []byte, obj interface{}) error {
-	return bson.Unmarshal(data, obj)
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	if array, ok := b.arrayValue(data, obj); ok {
+		return array.UnmarshalWithRegistry(mgocompat.Registry, obj)
+	}
+
+	return bson.UnmarshalWithRegistry(mgocompat.Registry, data, obj)
 }
 
 // ContentType returns the content type for this codec.
@@ -32,3 +118,85 @@ func (b *BsonCodec) FileExtension() string {
 func (b *BsonCodec) CanMarshalWithCallback() bool {
 	return false
 }
+
+// arrayConvention gets the array convention for this codec.
+func (b *BsonCodec) arrayConvention() string {
+	if b.ArrayConvention == "" {
+		return ArrayConventionDocument
+	}
+	return b.ArrayConvention
+}
+
+// arrayValue gets the array held in the data if it should be unmarshalled into
+// obj as an array, according to the array conventions.
+func (b *BsonCodec) arrayValue(data []byte, obj interface{}) (bson.RawValue, bool) {
+
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return bson.RawValue{}, false
+	}
+
+	doc := bson.Raw(data)
+	elements, err := doc.Elements()
+	if err != nil {
+		return bson.RawValue{}, false
+	}
+
+	wrapped := len(elements) == 1 && elements[0].Key() == ArrayWrapperKey && elements[0].Value().Type == bsontype.Array
+	whole := bson.RawValue{Type: bsontype.Array, Value: data}
+
+	target := rv.Elem()
+
+	if isArray(target) {
+
+		// either convention is accepted when the target is an array
+		if wrapped {
+			return elements[0].Value(), true
+		}
+		return whole, true
+
+	} else if target.Kind() == reflect.Interface && target.NumMethod() == 0 {
+
+		switch b.arrayConvention() {
+		case ArrayConventionWrap:
+			if wrapped {
+				return elements[0].Value(), true
+			}
+		case ArrayConventionDocument:
+			if len(elements) > 0 && isArrayDocument(elements) {
+				return whole, true
+			}
+		}
+
+	}
+
+	return bson.RawValue{}, false
+}
+
+// isArray gets whether the value is an array or slice that should be encoded
+// using an array convention, rather than as a document.
+func isArray(value reflect.Value) bool {
+
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return false
+	}
+
+	// documents and binary data are not arrays
+	valueType := value.Type()
+	if valueType == typeD || valueType == typeRaw || valueType.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+
+	return true
+}
+
+// isArrayDocument gets whether the elements of a document have the keys of
+// a BSON array ("0", "1", ...).
+func isArrayDocument(elements []bson.RawElement) bool {
+	for index, element := range elements {
+		if element.Key() != strconv.Itoa(index) {
+			return false
+		}
+	}
+	return true
+}
@@ -4,7 +4,10 @@ import (
 	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"testing"
+	"time"
 )
 
 func TestInterface(t *testing.T) {
@@ -45,6 +48,276 @@ func TestUnmarshal(t *testing.T) {
 
 }
 
+func TestUnmarshal_InvalidTarget(t *testing.T) {
+
+	codec := new(BsonCodec)
+	bsonData := []byte{0x15, 0x0, 0x0, 0x0, 0x2, 0x6e, 0x61, 0x6d, 0x65, 0x0, 0x6, 0x0, 0x0, 0x0, 0x54, 0x79, 0x6c, 0x65, 0x72, 0x0, 0x0}
+
+	for _, target := range []interface{}{nil, map[string]interface{}{}, (*map[string]interface{})(nil)} {
+		err := codec.Unmarshal(bsonData, target)
+		if assert.IsType(t, &InvalidUnmarshalError{}, err) {
+			assert.Contains(t, err.Error(), "codecs: bson: Unmarshal(")
+		}
+	}
+
+}
+
+func TestMarshal_WireCompatibility(t *testing.T) {
+
+	codec := new(BsonCodec)
+
+	// the bytes that mgo produced for the same document
+	expectedResult := []byte{0x84, 0x0, 0x0, 0x0, 0x2, 0x6e, 0x61, 0x6d, 0x65, 0x0, 0x6, 0x0, 0x0, 0x0, 0x54, 0x79, 0x6c, 0x65, 0x72, 0x0, 0x10, 0x61, 0x67, 0x65, 0x0, 0x1e, 0x0, 0x0, 0x0, 0x1, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0xf8, 0x3f, 0x8, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x0, 0x1, 0x9, 0x6a, 0x6f, 0x69, 0x6e, 0x65, 0x64, 0x0, 0x0, 0x8e, 0xab, 0x87, 0x3d, 0x1, 0x0, 0x0, 0x4, 0x74, 0x61, 0x67, 0x73, 0x0, 0x19, 0x0, 0x0, 0x0, 0x2, 0x30, 0x0, 0x2, 0x0, 0x0, 0x0, 0x61, 0x0, 0x12, 0x31, 0x0, 0x2, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x3, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x0, 0x17, 0x0, 0x0, 0x0, 0x2, 0x63, 0x69, 0x74, 0x79, 0x0, 0x8, 0x0, 0x0, 0x0, 0x42, 0x6f, 0x75, 0x6c, 0x64, 0x65, 0x72, 0x0, 0x0, 0x0}
+
+	obj := bson.D{
+		{Key: "name", Value: "Tyler"},
+		{Key: "age", Value: 30},
+		{Key: "score", Value: 1.5},
+		{Key: "admin", Value: true},
+		{Key: "joined", Value: time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC)},
+		{Key: "tags", Value: []interface{}{"a", int64(2)}},
+		{Key: "address", Value: bson.D{{Key: "city", Value: "Boulder"}}},
+	}
+
+	bsonData, bsonError := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, bsonError) {
+		assert.Equal(t, expectedResult, bsonData)
+	}
+
+	var object map[string]interface{}
+
+	if assert.NoError(t, codec.Unmarshal(expectedResult, &object)) {
+		assert.Equal(t, "Tyler", object["name"])
+		assert.Equal(t, 30, object["age"])
+		assert.Equal(t, 1.5, object["score"])
+		assert.Equal(t, true, object["admin"])
+		assert.Equal(t, time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC), object["joined"])
+		assert.Equal(t, []interface{}{"a", int64(2)}, object["tags"])
+		assert.Equal(t, map[string]interface{}{"city": "Boulder"}, object["address"])
+	}
+
+}
+
+func TestRoundTrip_ObjectId(t *testing.T) {
+
+	codec := new(BsonCodec)
+	id, _ := primitive.ObjectIDFromHex("5149a4c0e4b0b4a5d5000001")
+
+	var object struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+
+	bsonData, err := codec.Marshal(map[string]interface{}{"_id": id}, nil)
+
+	if assert.NoError(t, err) && assert.NoError(t, codec.Unmarshal(bsonData, &object)) {
+		assert.Equal(t, id, object.ID)
+	}
+
+	var generic map[string]interface{}
+
+	if assert.NoError(t, codec.Unmarshal(bsonData, &generic)) {
+		assert.Equal(t, id, generic["_id"])
+	}
+
+}
+
+func TestRoundTrip_Decimal128(t *testing.T) {
+
+	codec := new(BsonCodec)
+	price, _ := primitive.ParseDecimal128("1234567890.123456789012345678901234")
+
+	bsonData, err := codec.Marshal(map[string]interface{}{"price": price}, nil)
+
+	var object map[string]interface{}
+
+	if assert.NoError(t, err) && assert.NoError(t, codec.Unmarshal(bsonData, &object)) {
+		if assert.IsType(t, primitive.Decimal128{}, object["price"]) {
+			assert.Equal(t, price.String(), object["price"].(primitive.Decimal128).String())
+		}
+	}
+
+}
+
+func TestRoundTrip_Binary(t *testing.T) {
+
+	codec := new(BsonCodec)
+
+	obj := map[string]interface{}{
+		"generic": []byte{0x01, 0x02},
+		"uuid":    primitive.Binary{Subtype: 0x04, Data: []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}},
+		"md5":     primitive.Binary{Subtype: 0x05, Data: []byte{0xd4, 0x1d, 0x8c, 0xd9}},
+		"custom":  primitive.Binary{Subtype: 0x80, Data: []byte{0xff}},
+	}
+
+	bsonData, err := codec.Marshal(obj, nil)
+
+	var object map[string]interface{}
+
+	if assert.NoError(t, err) && assert.NoError(t, codec.Unmarshal(bsonData, &object)) {
+		assert.Equal(t, []byte{0x01, 0x02}, object["generic"], "Generic binary should decode as []byte")
+		assert.Equal(t, obj["uuid"], object["uuid"])
+		assert.Equal(t, obj["md5"], object["md5"])
+		assert.Equal(t, obj["custom"], object["custom"])
+	}
+
+}
+
+func TestRoundTrip_DateTime(t *testing.T) {
+
+	codec := new(BsonCodec)
+
+	// BSON datetimes have millisecond precision
+	when := time.Date(2013, 3, 20, 12, 30, 15, 123000000, time.UTC)
+
+	var object struct {
+		When time.Time
+	}
+
+	bsonData, err := codec.Marshal(map[string]interface{}{"when": when.In(time.FixedZone("MST", -7*60*60))}, nil)
+
+	if assert.NoError(t, err) && assert.NoError(t, codec.Unmarshal(bsonData, &object)) {
+		assert.True(t, when.Equal(object.When), "%s should equal %s", object.When, when)
+	}
+
+}
+
+func TestRoundTrip_NestedDocuments(t *testing.T) {
+
+	codec := new(BsonCodec)
+
+	type address struct {
+		City    string
+		Country string `bson:"country,omitempty"`
+	}
+	type person struct {
+		Name      string
+		Addresses []address
+		Meta      map[string]interface{}
+	}
+
+	obj := person{
+		Name:      "Tyler",
+		Addresses: []address{{City: "Boulder", Country: "USA"}, {City: "London"}},
+		Meta:      map[string]interface{}{"level": map[string]interface{}{"deep": "value"}},
+	}
+
+	bsonData, err := codec.Marshal(obj, nil)
+
+	var object person
+
+	if assert.NoError(t, err) && assert.NoError(t, codec.Unmarshal(bsonData, &object)) {
+		assert.Equal(t, obj.Name, object.Name)
+		assert.Equal(t, obj.Addresses, object.Addresses)
+		assert.Equal(t, map[string]interface{}{"deep": "value"}, object.Meta["level"])
+	}
+
+}
+
+func TestMarshal_Array(t *testing.T) {
+
+	codec := new(BsonCodec)
+
+	// codecs.PublicData produces []interface{} for slices
+	obj := []interface{}{map[string]interface{}{"name": "Mat"}, map[string]interface{}{"name": "Tyler"}}
+
+	bsonData, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+
+		var doc bson.D
+
+		if assert.NoError(t, codec.Unmarshal(bsonData, &doc)) && assert.Equal(t, 2, len(doc)) {
+			assert.Equal(t, "0", doc[0].Key)
+			assert.Equal(t, "1", doc[1].Key)
+		}
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(bsonData, &object)) {
+			assert.Equal(t, []interface{}{bson.M{"name": "Mat"}, bson.M{"name": "Tyler"}}, object)
+		}
+
+		var items []map[string]string
+
+		if assert.NoError(t, codec.Unmarshal(bsonData, &items)) {
+			assert.Equal(t, []map[string]string{{"name": "Mat"}, {"name": "Tyler"}}, items)
+		}
+
+	}
+
+}
+
+func TestMarshal_Array_Wrap(t *testing.T) {
+
+	codec := &BsonCodec{ArrayConvention: ArrayConventionWrap}
+
+	bsonData, err := codec.Marshal([]string{"Mat", "Tyler"}, nil)
+
+	if assert.NoError(t, err) {
+
+		var doc map[string]interface{}
+
+		if assert.NoError(t, bson.Unmarshal(bsonData, &doc)) {
+			assert.Equal(t, bson.A{"Mat", "Tyler"}, doc[ArrayWrapperKey])
+		}
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(bsonData, &object)) {
+			assert.Equal(t, []interface{}{"Mat", "Tyler"}, object)
+		}
+
+		var items []string
+
+		if assert.NoError(t, new(BsonCodec).Unmarshal(bsonData, &items), "Slices should accept either convention") {
+			assert.Equal(t, []string{"Mat", "Tyler"}, items)
+		}
+
+	}
+
+}
+
+func TestMarshal_Array_Option(t *testing.T) {
+
+	codec := new(BsonCodec)
+
+	bsonData, err := codec.Marshal([]int{1, 2}, map[string]interface{}{OptionArrayConvention: ArrayConventionWrap})
+
+	if assert.NoError(t, err) {
+
+		var doc map[string]interface{}
+
+		if assert.NoError(t, codec.Unmarshal(bsonData, &doc)) {
+			assert.Equal(t, []interface{}{1, 2}, doc[ArrayWrapperKey])
+		}
+
+	}
+
+	_, err = codec.Marshal([]int{1, 2}, map[string]interface{}{OptionArrayConvention: "unknown"})
+
+	assert.Equal(t, ErrorUnknownArrayConvention, err)
+
+}
+
+func TestUnmarshal_DocumentIntoInterface(t *testing.T) {
+
+	codec := new(BsonCodec)
+
+	bsonData, err := codec.Marshal(map[string]interface{}{"0": "zero", "2": "two"}, nil)
+
+	if assert.NoError(t, err) {
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(bsonData, &object)) {
+			assert.Equal(t, bson.M{"0": "zero", "2": "two"}, object, "Non-sequential keys are not an array")
+		}
+
+	}
+
+}
+
 func TestResponseContentType(t *testing.T) {
 
 	codec := new(BsonCodec)
@@ -64,3 +337,28 @@ func TestCanMarshalWithCallback(t *testing.T) {
 	assert.False(t, codec.CanMarshalWithCallback())
 
 }
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(BsonCodec)
+
+	// seed with marshalled objects, so the fuzzer starts from valid data
+	for _, object := range []interface{}{
+		map[string]interface{}{"name": "Mat", "age": 30, "tags": []interface{}{"a", "b"}},
+		map[string]interface{}{"nested": map[string]interface{}{"ok": true, "ratio": 0.5}},
+	} {
+		data, err := codec.Marshal(object, nil)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0x05, 0x00, 0x00, 0x00, 0x00})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
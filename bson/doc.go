@@ -1,2 +1,7 @@
 // A codec for handling BSON encoding and decoding
+//
+// The codec uses the BSON implementation from the MongoDB Go driver
+// (go.mongodb.org/mongo-driver/bson), so BSON specific types such as
+// primitive.ObjectID, primitive.Decimal128 and primitive.Binary can be used in
+// the objects being encoded.
 package bson
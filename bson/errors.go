@@ -0,0 +1,22 @@
+package bson
+
+import (
+	"reflect"
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: bson: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: bson: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: bson: Unmarshal(nil " + e.Type.String() + ")"
+}
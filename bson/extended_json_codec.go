@@ -0,0 +1,107 @@
+package bson
+
+import (
+	"bytes"
+	jsonEncoding "encoding/json"
+	"github.com/stretchr/codecs/constants"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/mgocompat"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"reflect"
+)
+
+const (
+	// OptionExtendedJsonCanonical is the option key used to choose between canonical
+	// (true) and relaxed (false) Extended JSON when marshalling.  It overrides the
+	// codec's Canonical field.
+	OptionExtendedJsonCanonical string = "options.bson.extjson.canonical"
+)
+
+// extendedJsonArrayKey is the key used to hold top-level arrays while they are
+// converted, since Extended JSON documents cannot be arrays.
+const extendedJsonArrayKey string = "a"
+
+// ExtendedJsonCodec converts objects to and from MongoDB Extended JSON (v2).
+//
+// Unlike JSON, Extended JSON preserves BSON types such as primitive.ObjectID,
+// primitive.Decimal128, primitive.Binary and datetimes, so documents can be moved
+// between services and browsers without losing type information.
+//
+// Relaxed mode (the default) writes numbers and dates in a more readable form,
+// while canonical mode preserves the exact BSON type of every value.
+type ExtendedJsonCodec struct {
+
+	// Canonical is whether canonical, rather than relaxed, Extended JSON is used.
+	Canonical bool
+}
+
+// Marshal converts an object to Extended JSON.
+func (c *ExtendedJsonCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	canonical := c.Canonical
+	if optionCanonical, ok := options[OptionExtendedJsonCanonical].(bool); ok {
+		canonical = optionCanonical
+	}
+
+	if !isArray(reflect.ValueOf(object)) {
+		return bson.MarshalExtJSONWithRegistry(mgocompat.Registry, object, canonical, false)
+	}
+
+	// marshal the array inside a document, then pull the array back out
+	data, err := bson.MarshalExtJSONWithRegistry(mgocompat.Registry, primitive.D{{Key: extendedJsonArrayKey, Value: object}}, canonical, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]jsonEncoding.RawMessage
+	if err := jsonEncoding.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc[extendedJsonArrayKey], nil
+}
+
+// Unmarshal converts Extended JSON into an object.
+//
+// Both canonical and relaxed Extended JSON are accepted.
+func (c *ExtendedJsonCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '[' {
+		return bson.UnmarshalExtJSONWithRegistry(mgocompat.Registry, data, false, obj)
+	}
+
+	// unmarshal the array inside a document, then decode the array
+	wrapped := make([]byte, 0, len(data)+len(extendedJsonArrayKey)+5)
+	wrapped = append(wrapped, `{"`+extendedJsonArrayKey+`":`...)
+	wrapped = append(wrapped, data...)
+	wrapped = append(wrapped, '}')
+
+	var doc bson.Raw
+	if err := bson.UnmarshalExtJSONWithRegistry(mgocompat.Registry, wrapped, false, &doc); err != nil {
+		return err
+	}
+
+	return doc.Lookup(extendedJsonArrayKey).UnmarshalWithRegistry(mgocompat.Registry, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *ExtendedJsonCodec) ContentType() string {
+	return constants.ContentTypeExtendedJSON
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *ExtendedJsonCodec) FileExtension() string {
+	return constants.FileExtensionExtendedJSON
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *ExtendedJsonCodec) CanMarshalWithCallback() bool {
+	return false
+}
@@ -0,0 +1,143 @@
+package bson
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func TestExtendedJson_Interface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(ExtendedJsonCodec))
+
+}
+
+func TestExtendedJson_Marshal_Relaxed(t *testing.T) {
+
+	codec := new(ExtendedJsonCodec)
+	id, _ := primitive.ObjectIDFromHex("5149a4c0e4b0b4a5d5000001")
+
+	obj := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "count", Value: int64(5)},
+		{Key: "joined", Value: time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC)},
+		{Key: "data", Value: []byte{0x01, 0x02}},
+	}
+
+	data, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"_id":{"$oid":"5149a4c0e4b0b4a5d5000001"},"count":5,"joined":{"$date":"2013-03-20T12:00:00Z"},"data":{"$binary":{"base64":"AQI=","subType":"00"}}}`, string(data))
+	}
+
+}
+
+func TestExtendedJson_Marshal_Canonical(t *testing.T) {
+
+	codec := &ExtendedJsonCodec{Canonical: true}
+
+	obj := bson.D{
+		{Key: "count", Value: int64(5)},
+		{Key: "joined", Value: time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC)},
+	}
+
+	data, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"count":{"$numberLong":"5"},"joined":{"$date":{"$numberLong":"1363780800000"}}}`, string(data))
+	}
+
+	// the option overrides the codec
+	data, err = codec.Marshal(obj, map[string]interface{}{OptionExtendedJsonCanonical: false})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"count":5,"joined":{"$date":"2013-03-20T12:00:00Z"}}`, string(data))
+	}
+
+}
+
+func TestExtendedJson_Unmarshal(t *testing.T) {
+
+	codec := new(ExtendedJsonCodec)
+	id, _ := primitive.ObjectIDFromHex("5149a4c0e4b0b4a5d5000001")
+	price, _ := primitive.ParseDecimal128("9.99")
+
+	for _, data := range []string{
+		`{"_id":{"$oid":"5149a4c0e4b0b4a5d5000001"},"count":{"$numberLong":"5"},"price":{"$numberDecimal":"9.99"},"joined":{"$date":{"$numberLong":"1363780800000"}},"tags":["a"]}`,
+		`{"_id":{"$oid":"5149a4c0e4b0b4a5d5000001"},"count":5,"price":{"$numberDecimal":"9.99"},"joined":{"$date":"2013-03-20T12:00:00Z"},"tags":["a"]}`,
+	} {
+
+		var object map[string]interface{}
+
+		if assert.NoError(t, codec.Unmarshal([]byte(data), &object), data) {
+			assert.Equal(t, id, object["_id"])
+			assert.Equal(t, price, object["price"])
+			assert.True(t, time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC).Equal(object["joined"].(time.Time)))
+			assert.Equal(t, []interface{}{"a"}, object["tags"])
+		}
+
+	}
+
+}
+
+func TestExtendedJson_Unmarshal_InvalidTarget(t *testing.T) {
+
+	codec := new(ExtendedJsonCodec)
+
+	for _, data := range []string{`{"name":"Mat"}`, `["a"]`} {
+		for _, target := range []interface{}{nil, map[string]interface{}{}, (*map[string]interface{})(nil)} {
+			assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal([]byte(data), target), data)
+		}
+	}
+
+}
+
+func TestExtendedJson_Array(t *testing.T) {
+
+	codec := new(ExtendedJsonCodec)
+	id, _ := primitive.ObjectIDFromHex("5149a4c0e4b0b4a5d5000001")
+
+	data, err := codec.Marshal([]interface{}{map[string]interface{}{"_id": id}, int64(2)}, nil)
+
+	if assert.NoError(t, err) {
+
+		assert.Equal(t, `[{"_id":{"$oid":"5149a4c0e4b0b4a5d5000001"}},2]`, string(data))
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(data, &object)) {
+			assert.Equal(t, []interface{}{bson.M{"_id": id}, 2}, object)
+		}
+
+	}
+
+}
+
+func TestExtendedJson_ContentType(t *testing.T) {
+
+	codec := new(ExtendedJsonCodec)
+	assert.Equal(t, constants.ContentTypeExtendedJSON, codec.ContentType())
+	assert.Equal(t, constants.FileExtensionExtendedJSON, codec.FileExtension())
+	assert.False(t, codec.CanMarshalWithCallback())
+
+}
+
+func FuzzExtendedJson_Unmarshal(f *testing.F) {
+
+	codec := new(ExtendedJsonCodec)
+
+	f.Add([]byte(`{"name":"Mat","age":{"$numberInt":"30"}}`))
+	f.Add([]byte(`{"when":{"$date":{"$numberLong":"1363780800000"}}}`))
+	f.Add([]byte(`{"id":{"$oid":"5f1d7a1b2c3d4e5f6a7b8c9d"}}`))
+	f.Add([]byte(`[1, 2`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
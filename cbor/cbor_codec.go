@@ -0,0 +1,193 @@
+package cbor
+
+import (
+	cborEncoding "github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// suffixCBOR is the structured syntax suffix (RFC 6839) of CBOR based content
+// types, such as application/senml+cbor.
+const suffixCBOR string = "+cbor"
+
+// ModeOptions configures the encoding and decoding modes used by a CborCodec.
+type ModeOptions struct {
+
+	// Deterministic encodes using the core deterministic encoding requirements
+	// (RFC 8949 section 4.2.1), so the same value always produces the same bytes.
+	Deterministic bool
+
+	// MapType is the type of map to make when decoding a map into an interface{}.
+	MapType reflect.Type
+
+	// MaxNestedLevels is the maximum depth of nested arrays, maps and tags allowed
+	// when decoding.
+	MaxNestedLevels int
+
+	// MaxArrayElements is the maximum number of elements allowed in an array when
+	// decoding.
+	MaxArrayElements int
+
+	// MaxMapPairs is the maximum number of key/value pairs allowed in a map when
+	// decoding.
+	MaxMapPairs int
+
+	// Tags are additional CBOR tags to register on the modes.
+	Tags []Tag
+}
+
+// Tag describes a CBOR tag.
+type Tag struct {
+
+	// Type is the Go type that is encoded as the content of this tag.
+	Type reflect.Type
+
+	// Number is the CBOR tag number.
+	Number uint64
+}
+
+// DefaultModeOptions gets the ModeOptions used by a CborCodec that was not made
+// with NewCborCodec.
+//
+// Maps are decoded as map[string]interface{}, so decoded objects are the same shape
+// as those produced by the other codecs.
+func DefaultModeOptions() ModeOptions {
+	return ModeOptions{
+		MapType:          reflect.TypeOf(map[string]interface{}(nil)),
+		MaxNestedLevels:  32,
+		MaxArrayElements: 131072,
+		MaxMapPairs:      131072,
+	}
+}
+
+// CborCodec converts objects to and from CBOR.
+//
+// The zero value uses DefaultModeOptions.  Use NewCborCodec to configure the
+// modes.
+type CborCodec struct {
+	encMode  cborEncoding.EncMode
+	decMode  cborEncoding.DecMode
+	modeOnce sync.Once
+}
+
+// NewCborCodec makes a new CborCodec that uses modes configured with the specified
+// options.
+func NewCborCodec(options ModeOptions) (*CborCodec, error) {
+
+	encMode, decMode, err := newModes(options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(CborCodec)
+	c.modeOnce.Do(func() {
+		c.encMode = encMode
+		c.decMode = decMode
+	})
+
+	return c, nil
+}
+
+// newModes makes a new cbor.EncMode and cbor.DecMode configured with the specified
+// options.
+func newModes(options ModeOptions) (cborEncoding.EncMode, cborEncoding.DecMode, error) {
+
+	encOptions := cborEncoding.EncOptions{}
+	if options.Deterministic {
+		encOptions = cborEncoding.CoreDetEncOptions()
+	}
+	encOptions.Time = cborEncoding.TimeUnixDynamic
+	encOptions.TimeTag = cborEncoding.EncTagRequired
+	encOptions.BigIntConvert = cborEncoding.BigIntConvertShortest
+
+	decOptions := cborEncoding.DecOptions{
+		DefaultMapType:   options.MapType,
+		MaxNestedLevels:  options.MaxNestedLevels,
+		MaxArrayElements: options.MaxArrayElements,
+		MaxMapPairs:      options.MaxMapPairs,
+		TimeTag:          cborEncoding.DecTagOptional,
+	}
+
+	tags := cborEncoding.NewTagSet()
+	for _, tag := range options.Tags {
+		if err := tags.Add(cborEncoding.TagOptions{EncTag: cborEncoding.EncTagRequired, DecTag: cborEncoding.DecTagOptional}, tag.Type, tag.Number); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	encMode, err := encOptions.EncModeWithTags(tags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decMode, err := decOptions.DecModeWithTags(tags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return encMode, decMode, nil
+}
+
+// initModes makes the modes from the DefaultModeOptions, if they have not
+// already been made.
+func (c *CborCodec) initModes() {
+	c.modeOnce.Do(func() {
+		// the default options have valid limits and no tags, so cannot fail
+		c.encMode, c.decMode, _ = newModes(DefaultModeOptions())
+	})
+}
+
+// EncMode gets the cbor.EncMode used by this codec.
+func (c *CborCodec) EncMode() cborEncoding.EncMode {
+	c.initModes()
+	return c.encMode
+}
+
+// DecMode gets the cbor.DecMode used by this codec.
+func (c *CborCodec) DecMode() cborEncoding.DecMode {
+	c.initModes()
+	return c.decMode
+}
+
+// Marshal converts an object to CBOR.
+func (c *CborCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+	return c.EncMode().Marshal(object)
+}
+
+// Unmarshal converts CBOR into an object.
+//
+// The obj must be a non-nil pointer, into which the data is decoded directly.
+func (c *CborCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	return c.DecMode().Unmarshal(data, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *CborCodec) ContentType() string {
+	return constants.ContentTypeCBOR
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *CborCodec) FileExtension() string {
+	return constants.FileExtensionCBOR
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *CborCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// ContentTypeSupported returns true if the passed in content type is CBOR, or
+// has the +cbor structured syntax suffix.
+func (c *CborCodec) ContentTypeSupported(contentType string) bool {
+	return contentType == c.ContentType() || strings.HasSuffix(contentType, suffixCBOR)
+}
@@ -0,0 +1,228 @@
+package cbor
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/objx"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type point struct {
+	X, Y int
+}
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(CborCodec), "CborCodec")
+	assert.Implements(t, (*codecs.ContentTypeMatcherCodec)(nil), new(CborCodec), "CborCodec")
+
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	codec := new(CborCodec)
+
+	obj := map[string]interface{}{
+		"name":    "Mat",
+		"age":     30,
+		"address": objx.MSI("city", "Boulder"),
+		"animals": []interface{}{"Dog", "Cat"},
+		"data":    []byte{0x01, 0x02},
+	}
+
+	cborData, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(cborData, &object)) {
+			assert.Equal(t, map[string]interface{}{
+				"name":    "Mat",
+				"age":     uint64(30),
+				"address": map[string]interface{}{"city": "Boulder"},
+				"animals": []interface{}{"Dog", "Cat"},
+				"data":    []byte{0x01, 0x02},
+			}, object)
+		}
+
+	}
+
+}
+
+func TestMarshal_Deterministic(t *testing.T) {
+
+	codec, err := NewCborCodec(ModeOptions{Deterministic: true})
+
+	if assert.NoError(t, err) {
+
+		cborData, err := codec.Marshal(map[string]interface{}{"aa": 3, "b": 1, "a": 2}, nil)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, []byte{0xa3, 0x61, 0x61, 0x02, 0x61, 0x62, 0x01, 0x62, 0x61, 0x61, 0x03}, cborData)
+		}
+
+	}
+
+}
+
+func TestTime(t *testing.T) {
+
+	codec := new(CborCodec)
+	joined := time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	cborData, err := codec.Marshal(joined, nil)
+
+	if assert.NoError(t, err) {
+
+		// tag 1 (epoch-based datetime)
+		assert.Equal(t, []byte{0xc1, 0x1a, 0x51, 0x49, 0xa4, 0xc0}, cborData)
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(cborData, &object)) && assert.IsType(t, time.Time{}, object) {
+			assert.True(t, joined.Equal(object.(time.Time)))
+		}
+
+	}
+
+	// tag 0 (standard datetime string)
+	var decoded time.Time
+
+	if assert.NoError(t, codec.Unmarshal(append([]byte{0xc0, 0x74}, "2013-03-20T12:00:00Z"...), &decoded)) {
+		assert.True(t, joined.Equal(decoded))
+	}
+
+}
+
+func TestBigNumbers(t *testing.T) {
+
+	codec := new(CborCodec)
+	number := new(big.Int).Lsh(big.NewInt(1), 64)
+
+	cborData, err := codec.Marshal(number, nil)
+
+	if assert.NoError(t, err) {
+
+		// tag 2 (unsigned bignum)
+		assert.Equal(t, []byte{0xc2, 0x49, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, cborData)
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(cborData, &object)) {
+			assert.Equal(t, *number, object)
+		}
+
+	}
+
+	// big numbers that fit are encoded as integers
+	cborData, err = codec.Marshal(big.NewInt(-1), nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x20}, cborData)
+	}
+
+}
+
+func TestTags(t *testing.T) {
+
+	codec, err := NewCborCodec(ModeOptions{Tags: []Tag{{Type: reflect.TypeOf(point{}), Number: 1000}}})
+
+	if assert.NoError(t, err) {
+
+		cborData, err := codec.Marshal(point{1, 2}, nil)
+
+		if assert.NoError(t, err) {
+
+			assert.Equal(t, []byte{0xd9, 0x03, 0xe8}, cborData[:3])
+
+			var object point
+
+			if assert.NoError(t, codec.Unmarshal(cborData, &object)) {
+				assert.Equal(t, point{1, 2}, object)
+			}
+
+		}
+
+	}
+
+}
+
+func TestUnmarshal_Limits(t *testing.T) {
+
+	codec, err := NewCborCodec(ModeOptions{MaxNestedLevels: 4, MaxArrayElements: 16, MaxMapPairs: 16})
+
+	if assert.NoError(t, err) {
+
+		var object interface{}
+
+		// five nested arrays
+		assert.Error(t, codec.Unmarshal([]byte{0x81, 0x81, 0x81, 0x81, 0x80}, &object))
+
+		// an array of 17 elements
+		assert.Error(t, codec.Unmarshal(append([]byte{0x91}, make([]byte, 17)...), &object))
+
+		// an array claiming more elements than it has
+		assert.Error(t, codec.Unmarshal([]byte{0x9a, 0xff, 0xff, 0xff, 0xff}, &object))
+
+		assert.NoError(t, codec.Unmarshal(append([]byte{0x90}, make([]byte, 16)...), &object))
+
+	}
+
+	_, err = NewCborCodec(ModeOptions{MaxNestedLevels: 1})
+	assert.Error(t, err)
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	codec := new(CborCodec)
+	var object interface{}
+
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal([]byte{0xa0}, object))
+	assert.Error(t, codec.Unmarshal([]byte{0xa1}, &object))
+
+}
+
+func TestContentType(t *testing.T) {
+
+	codec := new(CborCodec)
+
+	assert.Equal(t, constants.ContentTypeCBOR, codec.ContentType())
+	assert.Equal(t, constants.FileExtensionCBOR, codec.FileExtension())
+	assert.False(t, codec.CanMarshalWithCallback())
+
+	assert.True(t, codec.ContentTypeSupported("application/cbor"))
+	assert.True(t, codec.ContentTypeSupported("application/senml+cbor"))
+	assert.False(t, codec.ContentTypeSupported("application/json"))
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(CborCodec)
+
+	// seed with marshalled objects, so the fuzzer starts from valid data
+	for _, object := range []interface{}{
+		map[string]interface{}{"name": "Mat", "age": 30, "tags": []interface{}{"a", "b"}},
+		map[string]interface{}{"nested": map[string]interface{}{"ok": true, "ratio": 0.5}},
+	} {
+		data, err := codec.Marshal(object, nil)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0xc1, 0xfb, 0x7f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
@@ -0,0 +1,9 @@
+// A codec for handling CBOR (RFC 8949) encoding and decoding.
+//
+// time.Time values are encoded as epoch-based datetimes (tag 1), and big.Int
+// values that do not fit in a CBOR integer are encoded as bignums (tags 2 and 3).
+// Both decode back into time.Time and big.Int values.
+//
+// Decoding is limited by nesting depth, array length and map size, so data from
+// untrusted sources cannot exhaust memory.  See ModeOptions.
+package cbor
@@ -1,5 +1,9 @@
 package codecs
 
+import (
+	"strings"
+)
+
 // Codec is the interface to which a codec must conform.
 type Codec interface {
 
@@ -32,3 +36,58 @@ type ContentTypeMatcherCodec interface {
 	// can be handled by this codec, false otherwise
 	ContentTypeSupported(contentType string) bool
 }
+
+// ParameterizedCodec is a Codec that needs the parameters of a content type,
+// such as the boundary of multipart/form-data, in order to unmarshal data
+// sent with that content type.
+type ParameterizedCodec interface {
+	Codec
+
+	// WithParameters returns a Codec that unmarshals data sent with a content
+	// type that has the passed in parameters.
+	WithParameters(parameters map[string]string) Codec
+}
+
+// FileExtensionsCodec is a Codec that is represented by more than one file
+// extension, such as ".yaml" and ".yml".
+type FileExtensionsCodec interface {
+	Codec
+
+	// FileExtensions returns all of the file extensions by which the codec is
+	// represented.  The first is the extension returned by FileExtension.
+	FileExtensions() []string
+}
+
+// FileExtensions gets all of the file extensions by which the codec is
+// represented, using FileExtensions for codecs.FileExtensionsCodec values.
+//
+// Returns nil if the codec has no file extension.
+func FileExtensions(codec Codec) []string {
+
+	if extensionsCodec, ok := codec.(FileExtensionsCodec); ok {
+		return extensionsCodec.FileExtensions()
+	}
+
+	if extension := codec.FileExtension(); extension != "" {
+		return []string{extension}
+	}
+
+	return nil
+}
+
+// HasFileExtension gets whether the codec is represented by the file extension,
+// ignoring case.
+func HasFileExtension(codec Codec, extension string) bool {
+
+	if extension == "" {
+		return false
+	}
+
+	for _, codecExtension := range FileExtensions(codec) {
+		if strings.EqualFold(codecExtension, extension) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,37 @@
+package codecs
+
+import (
+	"github.com/stretchr/codecs/test"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// extensionsCodec is a codec with more than one file extension.
+type extensionsCodec struct {
+	test.TestCodec
+}
+
+func (c *extensionsCodec) FileExtensions() []string {
+	return []string{".yaml", ".yml"}
+}
+
+func TestFileExtensions(t *testing.T) {
+
+	codec := new(test.TestCodec)
+	codec.On("FileExtension").Return(".json")
+
+	assert.Equal(t, []string{".json"}, FileExtensions(codec))
+	assert.True(t, HasFileExtension(codec, ".JSON"))
+	assert.False(t, HasFileExtension(codec, ".xml"))
+	assert.False(t, HasFileExtension(codec, ""))
+
+	noExtension := new(test.TestCodec)
+	noExtension.On("FileExtension").Return("")
+
+	assert.Nil(t, FileExtensions(noExtension))
+	assert.False(t, HasFileExtension(noExtension, ""))
+
+	assert.Equal(t, []string{".yaml", ".yml"}, FileExtensions(new(extensionsCodec)))
+	assert.True(t, HasFileExtension(new(extensionsCodec), ".yml"))
+
+}
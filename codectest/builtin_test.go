@@ -0,0 +1,61 @@
+package codectest
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/bson"
+	"github.com/stretchr/codecs/csv"
+	"github.com/stretchr/codecs/form"
+	"github.com/stretchr/codecs/html"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/jsonp"
+	"github.com/stretchr/codecs/multipart"
+	"github.com/stretchr/codecs/protobuf"
+	"github.com/stretchr/codecs/services"
+	"github.com/stretchr/codecs/text"
+	"github.com/stretchr/codecs/toml"
+	"github.com/stretchr/codecs/xml"
+	"mime"
+	"testing"
+)
+
+// builtinSuite gets the suite for a built-in codec, skipping what it does not
+// support.
+func builtinSuite(codec codecs.Codec) *Suite {
+
+	suite := &Suite{Codec: codec}
+
+	switch codec.(type) {
+	case *xml.SimpleXmlCodec:
+		// simple XML only holds text, and an empty list or map is an empty
+		// element, which comes back as empty text
+		suite.Loose = true
+		suite.Skip = []string{"RoundTrip/empty collections"}
+	case *json.JsonCodec, *jsonp.JsonPCodec, *csv.CsvCodec:
+		// numbers are unmarshalled as float64, which cannot hold every int64
+		suite.Skip = []string{"RoundTrip/large integers"}
+	case *toml.TomlCodec:
+		// TOML has no null
+		suite.Skip = []string{"RoundTrip/empty values"}
+	case *form.FormCodec, *multipart.MultipartCodec:
+		// forms only hold text, and arrays of maps come back as maps keyed by index
+		suite.Loose = true
+		suite.Skip = []string{"RoundTrip/arrays of maps", "RoundTrip/empty collections"}
+	case *protobuf.ProtobufCodec, *text.TextCodec, *html.HtmlCodec:
+		// these do not unmarshal into maps
+		suite.Skip = []string{"RoundTrip", "Concurrency"}
+	}
+
+	return suite
+}
+
+func TestBuiltinCodecs(t *testing.T) {
+
+	// include the optional codecs too, without changing DefaultCodecs
+	allCodecs := append(append([]codecs.Codec(nil), services.DefaultCodecs...), new(bson.ExtendedJsonCodec))
+
+	for _, codec := range allCodecs {
+		mediaType, _, _ := mime.ParseMediaType(codec.ContentType())
+		t.Run(mediaType, builtinSuite(codec).Run)
+	}
+
+}
@@ -0,0 +1,66 @@
+package codectest
+
+import (
+	"math"
+)
+
+// Case is a value that a codec should be able to marshal and unmarshal back.
+type Case struct {
+
+	// Name is the name of the case, used to name its subtest.
+	Name string
+
+	// Value is the value to marshal.
+	Value map[string]interface{}
+}
+
+// Corpus is the set of cases the suite round-trips through a codec, unless
+// the Suite has a Corpus of its own.
+var Corpus = []Case{
+	{"flat", map[string]interface{}{
+		"name": "Mat",
+		"city": "Boulder",
+	}},
+	{"nested", map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":    "Mat",
+			"address": map[string]interface{}{"city": "Boulder"},
+		},
+	}},
+	{"arrays", map[string]interface{}{
+		"tags": []interface{}{"a", "b", "c"},
+	}},
+	{"arrays of maps", map[string]interface{}{
+		"pets": []interface{}{
+			map[string]interface{}{"name": "Dog"},
+			map[string]interface{}{"name": "Cat"},
+		},
+	}},
+	{"unicode", map[string]interface{}{
+		"greeting": "こんにちは, wörld 🎉",
+		"quotes":   `"quoted" <tag> & 'single'`,
+	}},
+	{"numbers", map[string]interface{}{
+		"small":    1,
+		"negative": -42,
+		"float":    1.5,
+		"large":    int64(1) << 53,
+	}},
+	{"large integers", map[string]interface{}{
+		"inexact": int64(1)<<53 + 1,
+		"max":     int64(math.MaxInt64),
+		"min":     int64(math.MinInt64),
+	}},
+	{"booleans", map[string]interface{}{
+		"yes": true,
+		"no":  false,
+	}},
+	{"empty values", map[string]interface{}{
+		"empty":   "",
+		"nothing": nil,
+	}},
+	{"empty collections", map[string]interface{}{
+		"list":   []interface{}{},
+		"object": map[string]interface{}{},
+	}},
+}
@@ -0,0 +1,14 @@
+// codectest package provides a conformance suite that checks a codec behaves
+// like the built-in codecs.
+//
+//    func TestMyCodec(t *testing.T) {
+//        codectest.Run(t, new(MyCodec))
+//    }
+//
+// Codecs that cannot handle everything in the Corpus can skip parts of the
+// suite with a Suite:
+//
+//    suite := &codectest.Suite{Codec: new(MyCodec), Skip: []string{"RoundTrip/nested"}, Loose: true}
+//    suite.Run(t)
+//
+package codectest
@@ -0,0 +1,343 @@
+package codectest
+
+import (
+	"fmt"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"math"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// concurrency is the number of goroutines that use the codec at once in the
+// Concurrency check.
+const concurrency int = 8
+
+// unknownContentType is a content type no codec should support.
+const unknownContentType string = "application/x-codectest-unknown"
+
+// Suite is the conformance suite for a codec.
+type Suite struct {
+
+	// Codec is the codec being checked.
+	Codec codecs.Codec
+
+	// Corpus is the cases to round-trip.  If nil, Corpus is used.
+	Corpus []Case
+
+	// MarshalOptions are passed to Marshal.  If nil, and the codec can marshal
+	// with a callback, the callback option is set.
+	MarshalOptions map[string]interface{}
+
+	// Skip lists the checks, or round-trip cases, the codec does not support,
+	// by the name of their subtest, such as "Concurrency", "RoundTrip" or
+	// "RoundTrip/nested".
+	Skip []string
+
+	// Loose compares values by their text after a round-trip, for codecs that do
+	// not keep the types of values (such as CSV, XML and forms).  Nil values
+	// then match empty strings.
+	Loose bool
+}
+
+// Run runs the conformance suite against the codec, with every check and the
+// whole Corpus.
+func Run(t *testing.T, codec codecs.Codec) {
+	(&Suite{Codec: codec}).Run(t)
+}
+
+// Run runs the conformance suite as subtests of t.
+func (s *Suite) Run(t *testing.T) {
+
+	s.run(t, "ContentType", s.testContentType)
+	s.run(t, "ContentTypeMatcher", s.testContentTypeMatcher)
+	s.run(t, "UnmarshalErrors", s.testUnmarshalErrors)
+
+	s.run(t, "RoundTrip", func(t *testing.T) {
+		for _, c := range s.corpus() {
+			c := c
+			s.run(t, "RoundTrip/"+c.Name, func(t *testing.T) {
+				s.testRoundTrip(t, c)
+			})
+		}
+	})
+
+	s.run(t, "Concurrency", s.testConcurrency)
+
+}
+
+// run runs the check as a subtest, unless its path (such as
+// "RoundTrip/nested") is skipped.
+func (s *Suite) run(t *testing.T, path string, check func(t *testing.T)) {
+	t.Run(path[strings.LastIndex(path, "/")+1:], func(t *testing.T) {
+		for _, skip := range s.Skip {
+			if skip == path {
+				t.Skip("skipped by the suite")
+			}
+		}
+		check(t)
+	})
+}
+
+// corpus gets the cases to round-trip.
+func (s *Suite) corpus() []Case {
+	if s.Corpus != nil {
+		return s.Corpus
+	}
+	return Corpus
+}
+
+// options gets the options passed to Marshal.
+func (s *Suite) options() map[string]interface{} {
+	if s.MarshalOptions == nil && s.Codec.CanMarshalWithCallback() {
+		return map[string]interface{}{constants.OptionKeyClientCallback: "callback"}
+	}
+	return s.MarshalOptions
+}
+
+// testContentType checks the content type and file extensions are well formed.
+func (s *Suite) testContentType(t *testing.T) {
+
+	contentType := s.Codec.ContentType()
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Errorf("ContentType() %q is not a valid media type: %s", contentType, err)
+	} else if !strings.HasPrefix(contentType, mediaType) {
+		t.Errorf("ContentType() %q should be lower case", contentType)
+	}
+
+	extension := s.Codec.FileExtension()
+	if extension != "" && (!strings.HasPrefix(extension, ".") || strings.ContainsAny(extension[1:], "./") || len(extension) == 1) {
+		t.Errorf("FileExtension() %q should be empty, or a dot followed by a name", extension)
+	}
+
+	if extensionsCodec, ok := s.Codec.(codecs.FileExtensionsCodec); ok {
+		extensions := extensionsCodec.FileExtensions()
+		if len(extensions) == 0 || extensions[0] != extension {
+			t.Errorf("FileExtensions() %q should start with FileExtension() %q", extensions, extension)
+		}
+	}
+
+}
+
+// testContentTypeMatcher checks a ContentTypeMatcherCodec supports its own
+// content type, and not others.
+func (s *Suite) testContentTypeMatcher(t *testing.T) {
+
+	matcher, ok := s.Codec.(codecs.ContentTypeMatcherCodec)
+	if !ok {
+		t.Skip("not a ContentTypeMatcherCodec")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(matcher.ContentType())
+
+	if !matcher.ContentTypeSupported(mediaType) {
+		t.Errorf("ContentTypeSupported(%q) should be true for the codec's own content type", mediaType)
+	}
+	if matcher.ContentTypeSupported(unknownContentType) {
+		t.Errorf("ContentTypeSupported(%q) should be false", unknownContentType)
+	}
+
+}
+
+// testUnmarshalErrors checks Unmarshal returns errors, rather than panicking,
+// for nil and non-pointer targets.
+func (s *Suite) testUnmarshalErrors(t *testing.T) {
+
+	data, err := s.Codec.Marshal(s.corpus()[0].Value, s.options())
+	if err != nil {
+		data = []byte("{}")
+	}
+
+	for _, target := range []interface{}{nil, map[string]interface{}{}, (*map[string]interface{})(nil)} {
+
+		err := catch(func() error {
+			return s.Codec.Unmarshal(data, target)
+		})
+
+		if err == nil {
+			t.Errorf("Unmarshal into %T should return an error", target)
+		} else if _, panicked := err.(*panicError); panicked {
+			t.Errorf("Unmarshal into %T: %s", target, err)
+		}
+
+	}
+
+}
+
+// testRoundTrip checks the case's value is the same after it is marshalled and
+// unmarshalled.
+func (s *Suite) testRoundTrip(t *testing.T, c Case) {
+
+	object, err := s.roundTrip(c.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, actual := normalize(c.Value, s.Loose), normalize(object, s.Loose)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("round-trip changed the value\nexpected: %#v\nactual:   %#v", expected, actual)
+	}
+
+}
+
+// testConcurrency checks the codec can be used by more than one goroutine at a
+// time.
+func (s *Suite) testConcurrency(t *testing.T) {
+
+	value := s.corpus()[0].Value
+	expected := normalize(value, s.Loose)
+
+	var waitGroup sync.WaitGroup
+	errs := make(chan error, concurrency)
+
+	for index := 0; index < concurrency; index++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			object, err := s.roundTrip(value)
+			if err == nil && !reflect.DeepEqual(expected, normalize(object, s.Loose)) {
+				err = fmt.Errorf("concurrent round-trip changed the value to %#v", object)
+			}
+			if err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+}
+
+// roundTrip marshals and unmarshals the value with the codec.
+func (s *Suite) roundTrip(value map[string]interface{}) (map[string]interface{}, error) {
+
+	var object map[string]interface{}
+
+	err := catch(func() error {
+
+		data, err := s.Codec.Marshal(value, s.options())
+		if err != nil {
+			return fmt.Errorf("Marshal: %s", err)
+		}
+
+		if err := s.Codec.Unmarshal(data, &object); err != nil {
+			return fmt.Errorf("Unmarshal %q: %s", data, err)
+		}
+
+		return nil
+	})
+
+	return object, err
+}
+
+// panicError is the error for a panic caught by catch.
+type panicError struct {
+	value interface{}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panicked: %v", e.value)
+}
+
+// catch calls the function, turning a panic into an error.
+func catch(function func() error) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = &panicError{value}
+		}
+	}()
+	return function()
+}
+
+// normalize converts a value into a form that can be compared whatever codec
+// unmarshalled it: maps become map[string]interface{}, arrays become
+// []interface{}, integers become int64 (or uint64 if too large) and other
+// numbers become float64, unless they are whole numbers that a float64 holds
+// exactly, which become int64.  If loose, numbers and booleans become strings,
+// and nil becomes "".
+func normalize(value interface{}, loose bool) interface{} {
+
+	if value == nil {
+		if loose {
+			return ""
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Map:
+
+		object := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			object[fmt.Sprint(key.Interface())] = normalize(rv.MapIndex(key).Interface(), loose)
+		}
+		return object
+
+	case reflect.Slice, reflect.Array:
+
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes())
+		}
+
+		items := make([]interface{}, rv.Len())
+		for index := range items {
+			items[index] = normalize(rv.Index(index).Interface(), loose)
+		}
+		return items
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if loose {
+			return strconv.FormatInt(rv.Int(), 10)
+		}
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() <= math.MaxInt64 {
+			return normalize(int64(rv.Uint()), loose)
+		}
+		if loose {
+			return strconv.FormatUint(rv.Uint(), 10)
+		}
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return normalizeFloat(rv.Float(), loose)
+	case reflect.Bool:
+		if loose {
+			return strconv.FormatBool(rv.Bool())
+		}
+		return rv.Bool()
+	case reflect.String:
+		return rv.String()
+	}
+
+	return value
+}
+
+// maxExactFloat is the largest whole number below which every whole number can
+// be held exactly by a float64.
+const maxExactFloat = 1 << 53
+
+// normalizeFloat gets the normalized form of a float, which is an int64 if it
+// is a whole number that could have been unmarshalled from an integer without
+// losing precision.
+func normalizeFloat(number float64, loose bool) interface{} {
+	if number == math.Trunc(number) && math.Abs(number) <= maxExactFloat {
+		return normalize(int64(number), loose)
+	}
+	if loose {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return number
+}
@@ -0,0 +1,141 @@
+package compression
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"io"
+)
+
+// The names of the supported content-codings, as used in the Accept-Encoding and
+// Content-Encoding headers.
+const (
+	CodingGzip     string = "gzip"
+	CodingDeflate  string = "deflate"
+	CodingBrotli   string = "br"
+	CodingZstd     string = "zstd"
+	CodingIdentity string = "identity"
+)
+
+// Coding is a content-coding that compresses data.
+type Coding interface {
+
+	// Name gets the name of the content-coding, such as "gzip".
+	Name() string
+
+	// NewWriter makes a writer that compresses data written to it into w.  The
+	// writer must be closed to flush the compressed data.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+
+	// NewReader makes a reader that decompresses the data read from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// GzipCoding is the gzip content-coding.
+type GzipCoding struct {
+
+	// Level is the compression level, from gzip.BestSpeed to gzip.BestCompression.
+	// If zero, gzip.DefaultCompression is used.
+	Level int
+}
+
+// Name gets the name of the content-coding.
+func (c *GzipCoding) Name() string {
+	return CodingGzip
+}
+
+// NewWriter makes a writer that compresses data written to it into w.
+func (c *GzipCoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, defaultLevel(c.Level, gzip.DefaultCompression))
+}
+
+// NewReader makes a reader that decompresses the data read from r.
+func (c *GzipCoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// DeflateCoding is the deflate content-coding, which is the zlib format.
+type DeflateCoding struct {
+
+	// Level is the compression level, from flate.BestSpeed to flate.BestCompression.
+	// If zero, flate.DefaultCompression is used.
+	Level int
+}
+
+// Name gets the name of the content-coding.
+func (c *DeflateCoding) Name() string {
+	return CodingDeflate
+}
+
+// NewWriter makes a writer that compresses data written to it into w.
+func (c *DeflateCoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriterLevel(w, defaultLevel(c.Level, flate.DefaultCompression))
+}
+
+// NewReader makes a reader that decompresses the data read from r.
+func (c *DeflateCoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+// BrotliCoding is the br (Brotli) content-coding.
+type BrotliCoding struct {
+
+	// Level is the compression level, from brotli.BestSpeed to brotli.BestCompression.
+	// If zero, brotli.DefaultCompression is used.
+	Level int
+}
+
+// Name gets the name of the content-coding.
+func (c *BrotliCoding) Name() string {
+	return CodingBrotli
+}
+
+// NewWriter makes a writer that compresses data written to it into w.
+func (c *BrotliCoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return brotli.NewWriterLevel(w, defaultLevel(c.Level, brotli.DefaultCompression)), nil
+}
+
+// NewReader makes a reader that decompresses the data read from r.
+func (c *BrotliCoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(brotli.NewReader(r)), nil
+}
+
+// ZstdCoding is the zstd (Zstandard) content-coding.
+type ZstdCoding struct {
+
+	// Level is the compression level, as a zstd level from 1 to 22.  If zero, the
+	// default level is used.
+	Level int
+}
+
+// Name gets the name of the content-coding.
+func (c *ZstdCoding) Name() string {
+	return CodingZstd
+}
+
+// NewWriter makes a writer that compresses data written to it into w.
+func (c *ZstdCoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if c.Level == 0 {
+		return zstd.NewWriter(w)
+	}
+	return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(c.Level)))
+}
+
+// NewReader makes a reader that decompresses the data read from r.
+func (c *ZstdCoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
+
+// defaultLevel gets the level, or the default level if it is zero.
+func defaultLevel(level, defaultLevel int) int {
+	if level == 0 {
+		return defaultLevel
+	}
+	return level
+}
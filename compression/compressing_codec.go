@@ -0,0 +1,123 @@
+package compression
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs"
+	"io"
+)
+
+// DefaultMaxDecompressedSize is the largest decompressed data, in bytes, that
+// Unmarshal accepts when the codec's MaxDecompressedSize is not set.
+const DefaultMaxDecompressedSize int64 = 32 << 20
+
+// NoLimit is the MaxDecompressedSize for accepting decompressed data of any
+// size.  Only use it when the compressed data is trusted, since a small amount
+// of compressed data can decompress to a very large amount.
+const NoLimit int64 = -1
+
+// ContentEncoder is the interface implemented by codecs whose output is
+// compressed with one or more content-codings.
+type ContentEncoder interface {
+
+	// ContentEncoding gets the value of the Content-Encoding header for the
+	// codec's output, such as "gzip".
+	ContentEncoding() string
+}
+
+// CompressingCodec wraps a codec, compressing the data it marshals and
+// decompressing the data before it is unmarshalled.
+//
+// CompressingCodecs can be nested to apply more than one content-coding, in
+// which case the innermost coding is applied first.
+type CompressingCodec struct {
+	codecs.Codec
+
+	// Coding is the content-coding used to compress the data.
+	Coding Coding
+
+	// MaxDecompressedSize is the largest decompressed data, in bytes, that
+	// Unmarshal accepts.  If zero, DefaultMaxDecompressedSize is used.  If
+	// negative (such as NoLimit), there is no limit.
+	MaxDecompressedSize int64
+}
+
+// NewCompressingCodec makes a CompressingCodec that compresses the output of
+// the codec with the coding.
+func NewCompressingCodec(codec codecs.Codec, coding Coding) *CompressingCodec {
+	return &CompressingCodec{Codec: codec, Coding: coding}
+}
+
+// Marshal marshals the object with the wrapped codec, and compresses the result.
+func (c *CompressingCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	data, err := c.Codec.Marshal(object, options)
+	if err != nil {
+		return nil, err
+	}
+
+	byteBuffer := new(bytes.Buffer)
+
+	writer, err := c.Coding.NewWriter(byteBuffer)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return byteBuffer.Bytes(), nil
+}
+
+// Unmarshal decompresses the data, and unmarshals the result into the object
+// with the wrapped codec.
+func (c *CompressingCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	reader, err := c.Coding.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	maxSize := c.MaxDecompressedSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxDecompressedSize
+	}
+
+	var limitedReader io.Reader = reader
+	if maxSize > 0 {
+		// read one byte more than the limit, to tell if it is exceeded
+		limitedReader = io.LimitReader(reader, maxSize+1)
+	}
+
+	decompressed, err := io.ReadAll(limitedReader)
+	if err != nil {
+		return err
+	}
+
+	if maxSize > 0 && int64(len(decompressed)) > maxSize {
+		return ErrorTooLarge
+	}
+
+	return c.Codec.Unmarshal(decompressed, obj)
+}
+
+// ContentEncoding gets the value of the Content-Encoding header for the data
+// marshalled by this codec, listing the codings in the order they are applied.
+func (c *CompressingCodec) ContentEncoding() string {
+	if encoder, ok := c.Codec.(ContentEncoder); ok {
+		return encoder.ContentEncoding() + ", " + c.Coding.Name()
+	}
+	return c.Coding.Name()
+}
+
+// FileExtensions returns all of the file extensions by which the wrapped codec is
+// represented.
+func (c *CompressingCodec) FileExtensions() []string {
+	return codecs.FileExtensions(c.Codec)
+}
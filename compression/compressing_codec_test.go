@@ -0,0 +1,186 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testCodings = []Coding{new(GzipCoding), new(DeflateCoding), new(BrotliCoding), new(ZstdCoding)}
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(CompressingCodec), "CompressingCodec")
+	assert.Implements(t, (*ContentEncoder)(nil), new(CompressingCodec), "CompressingCodec")
+
+	for _, coding := range testCodings {
+		assert.Implements(t, (*Coding)(nil), coding)
+	}
+
+}
+
+func TestCodingNames(t *testing.T) {
+
+	assert.Equal(t, "gzip", new(GzipCoding).Name())
+	assert.Equal(t, "deflate", new(DeflateCoding).Name())
+	assert.Equal(t, "br", new(BrotliCoding).Name())
+	assert.Equal(t, "zstd", new(ZstdCoding).Name())
+
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	obj := map[string]interface{}{"name": strings.Repeat("Mat", 100)}
+
+	for _, coding := range append(testCodings, &GzipCoding{Level: gzip.BestSpeed}, &ZstdCoding{Level: 19}, &BrotliCoding{Level: 11}) {
+
+		codec := NewCompressingCodec(new(json.JsonCodec), coding)
+
+		data, err := codec.Marshal(obj, nil)
+
+		if assert.NoError(t, err, coding.Name()) {
+
+			assert.True(t, len(data) < 300, coding.Name()+" should compress the data")
+
+			var object map[string]interface{}
+
+			if assert.NoError(t, codec.Unmarshal(data, &object), coding.Name()) {
+				assert.Equal(t, obj, object, coding.Name())
+			}
+
+		}
+
+	}
+
+}
+
+func TestMarshal_Gzip(t *testing.T) {
+
+	codec := NewCompressingCodec(new(json.JsonCodec), new(GzipCoding))
+
+	data, err := codec.Marshal(map[string]interface{}{"name": "Mat"}, nil)
+
+	if assert.NoError(t, err) {
+
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+
+		if assert.NoError(t, err) {
+			decompressed, _ := io.ReadAll(reader)
+			assert.Equal(t, `{"name":"Mat"}`, string(decompressed))
+		}
+
+	}
+
+	assert.Equal(t, "application/json", codec.ContentType())
+	assert.Equal(t, ".json", codec.FileExtension())
+
+}
+
+func TestNested(t *testing.T) {
+
+	codec := NewCompressingCodec(NewCompressingCodec(new(json.JsonCodec), new(GzipCoding)), new(BrotliCoding))
+
+	assert.Equal(t, "gzip, br", codec.ContentEncoding())
+
+	data, err := codec.Marshal(map[string]interface{}{"name": "Mat"}, nil)
+
+	if assert.NoError(t, err) {
+
+		var object map[string]interface{}
+
+		if assert.NoError(t, codec.Unmarshal(data, &object)) {
+			assert.Equal(t, "Mat", object["name"])
+		}
+
+		// only the outer coding removed leaves gzip data
+		_, err := gzip.NewReader(bytes.NewReader(data))
+		assert.Error(t, err)
+
+	}
+
+}
+
+func TestUnmarshal_TooLarge(t *testing.T) {
+
+	codec := NewCompressingCodec(new(json.JsonCodec), new(GzipCoding))
+
+	data, err := codec.Marshal(map[string]interface{}{"name": strings.Repeat("a", 1000)}, nil)
+
+	if assert.NoError(t, err) {
+
+		var object map[string]interface{}
+
+		codec.MaxDecompressedSize = 100
+		assert.Equal(t, ErrorTooLarge, codec.Unmarshal(data, &object))
+
+		codec.MaxDecompressedSize = 1011
+		assert.NoError(t, codec.Unmarshal(data, &object))
+
+	}
+
+}
+
+func TestUnmarshal_DefaultLimit(t *testing.T) {
+
+	codec := NewCompressingCodec(new(json.JsonCodec), new(GzipCoding))
+
+	// a small body that decompresses to more than the default limit
+	data, err := codec.Marshal(strings.Repeat("a", int(DefaultMaxDecompressedSize)), nil)
+
+	if assert.NoError(t, err) {
+
+		assert.True(t, len(data) < 100<<10)
+
+		var object interface{}
+		assert.Equal(t, ErrorTooLarge, codec.Unmarshal(data, &object))
+
+		codec.MaxDecompressedSize = NoLimit
+		assert.NoError(t, codec.Unmarshal(data, &object))
+
+	}
+
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+
+	var object map[string]interface{}
+
+	for _, coding := range testCodings {
+		codec := NewCompressingCodec(new(json.JsonCodec), coding)
+		assert.Error(t, codec.Unmarshal([]byte(`{"name":"Mat"}`), &object), coding.Name())
+	}
+
+}
+
+func TestFileExtensions(t *testing.T) {
+
+	codec := NewCompressingCodec(new(json.JsonCodec), new(GzipCoding))
+
+	assert.Equal(t, []string{".json"}, codec.FileExtensions())
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	for _, coding := range testCodings {
+		data, err := NewCompressingCodec(new(json.JsonCodec), coding).Marshal(map[string]interface{}{"name": "Mat"}, nil)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, coding := range testCodings {
+			codec := &CompressingCodec{Codec: new(json.JsonCodec), Coding: coding, MaxDecompressedSize: 1 << 20}
+			var object interface{}
+			codec.Unmarshal(data, &object)
+		}
+	})
+
+}
@@ -0,0 +1,6 @@
+// Content-codings (such as gzip) that compress the output of codecs.
+//
+// A CompressingCodec wraps any codec, compressing the data it marshals and
+// decompressing the data it unmarshals, so compressed responses can be sent and
+// compressed request bodies read without changing the codec.
+package compression
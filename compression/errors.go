@@ -0,0 +1,9 @@
+package compression
+
+import (
+	"errors"
+)
+
+// ErrorTooLarge is the error for when decompressed data is larger than the
+// MaxDecompressedSize of a CompressingCodec.
+var ErrorTooLarge = errors.New("codecs: compression: decompressed data is too large")
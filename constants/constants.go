@@ -5,21 +5,62 @@ package constants
 */
 
 const (
-	ContentTypeJSON      string = "application/json"
-	FileExtensionJSON    string = ".json"
-	ContentTypeJSONP     string = "text/javascript"
-	FileExtensionJSONP   string = ".js"
-	ContentTypeBSON      string = "application/bson"
-	FileExtensionBSON    string = ".bson"
-	ContentTypeMsgpack   string = "application/x-msgpack"
-	FileExtensionMsgpack string = ".msgpack"
-	ContentTypeCSV       string = "text/csv"
-	FileExtensionCSV     string = ".csv"
-	ContentTypeXML       string = "text/xml"
-	FileExtensionXML     string = ".xml"
+	ContentTypeJSON       string = "application/json"
+	FileExtensionJSON     string = ".json"
+	ContentTypeJSONP      string = "text/javascript"
+	FileExtensionJSONP    string = ".js"
+	ContentTypeBSON       string = "application/bson"
+	FileExtensionBSON     string = ".bson"
+	ContentTypeMsgpack    string = "application/x-msgpack"
+	FileExtensionMsgpack  string = ".msgpack"
+	ContentTypeCSV        string = "text/csv"
+	FileExtensionCSV      string = ".csv"
+	ContentTypeXML        string = "text/xml"
+	FileExtensionXML      string = ".xml"
+	ContentTypeYAML       string = "application/yaml"
+	FileExtensionYAML     string = ".yaml"
+	FileExtensionYML      string = ".yml"
+	ContentTypeTOML       string = "application/toml"
+	FileExtensionTOML     string = ".toml"
+	ContentTypeCBOR       string = "application/cbor"
+	FileExtensionCBOR     string = ".cbor"
+	ContentTypeProtobuf   string = "application/x-protobuf"
+	FileExtensionProtobuf string = ".pb"
+	ContentTypeForm       string = "application/x-www-form-urlencoded"
+	ContentTypeMultipart  string = "multipart/form-data"
+	ContentTypeText       string = "text/plain"
+	FileExtensionText     string = ".txt"
+	ContentTypeHTML       string = "text/html"
+	FileExtensionHTML     string = ".html"
+	FileExtensionHTM      string = ".htm"
+)
+
+/*
+	Optional Content Types
+*/
+
+const (
+	ContentTypeExtendedJSON   string = "application/x-extended-json"
+	FileExtensionExtendedJSON string = ".ejson"
+)
+
+/*
+	Problem Details (RFC 7807) Content Types
+*/
+
+const (
+	ContentTypeProblemJSON string = "application/problem+json"
+	ContentTypeProblemXML  string = "application/problem+xml"
 )
 
 const (
 	OptionKeyClientCallback string = "options.client.callback"
 	OptionKeyClientContext  string = "options.client.context"
+	OptionKeyMatchedType    string = "matched_type"
+
+	// OptionKeyResponseContentType is set in the options by codecs whose data needs
+	// a content type with parameters that change with each call, such as the
+	// boundary of multipart/form-data.  The data should be sent with this content
+	// type instead of the codec's ContentType.
+	OptionKeyResponseContentType string = "options.response.contenttype"
 )
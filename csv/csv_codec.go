@@ -39,7 +39,14 @@ func (c *CsvCodec) Marshal(object interface{}, options map[string]interface{}) (
 		}
 	case []interface{}:
 		for _, item := range object.([]interface{}) {
-			dataRows = append(dataRows, item.(map[string]interface{}))
+			switch row := item.(type) {
+			case map[string]interface{}:
+				dataRows = append(dataRows, row)
+			case objx.Map:
+				dataRows = append(dataRows, row.Value().ObjxMap())
+			default:
+				return nil, &UnsupportedTypeError{reflect.TypeOf(item)}
+			}
 		}
 	}
 
@@ -126,6 +133,10 @@ func (c *CsvCodec) Unmarshal(data []byte, obj interface{}) error {
 	}
 
 	reader := csv.NewReader(bytes.NewReader(data))
+
+	// rows may have fewer values than there are fields
+	reader.FieldsPerRecord = -1
+
 	records, readErr := reader.ReadAll()
 
 	if readErr != nil {
@@ -156,7 +167,9 @@ func (c *CsvCodec) Unmarshal(data []byte, obj interface{}) error {
 		}
 
 		// set the obj value
-		rv.Elem().Set(reflect.ValueOf(object))
+		if err := setValue(rv.Elem(), object); err != nil {
+			return err
+		}
 
 	} else {
 
@@ -181,7 +194,9 @@ func (c *CsvCodec) Unmarshal(data []byte, obj interface{}) error {
 		}
 
 		// set the obj value
-		rv.Elem().Set(reflect.ValueOf(rows))
+		if err := setValue(rv.Elem(), rows); err != nil {
+			return err
+		}
 
 	}
 
@@ -212,9 +227,30 @@ func (c *CsvCodec) ContentTypeSupported(contentType string) bool {
 	return contentType == c.ContentType()
 }
 
+// setValue sets the target to the unmarshalled value, if the target can hold it.
+func setValue(target reflect.Value, value interface{}) error {
+
+	valueOf := reflect.ValueOf(value)
+	if !valueOf.Type().AssignableTo(target.Type()) {
+		return &UnmarshalTypeError{valueOf.Type(), target.Type()}
+	}
+
+	target.Set(valueOf)
+
+	return nil
+}
+
 // mapFromFieldsAndRow makes a map[string]interface{} from the given fields and
 // row data.
+//
+// Rows with fewer values than there are fields leave the rest out, and rows with
+// more values return a RaggedRowError.
 func mapFromFieldsAndRow(fields, row []string) (map[string]interface{}, error) {
+
+	if len(row) > len(fields) {
+		return nil, &RaggedRowError{len(fields), len(row)}
+	}
+
 	m := make(map[string]interface{})
 
 	for index, item := range row {
@@ -125,6 +125,73 @@ func TestUnmarshal_SingleObject(t *testing.T) {
 
 }
 
+func TestUnmarshal_RaggedRows(t *testing.T) {
+
+	csvCodec := new(CsvCodec)
+
+	var obj interface{}
+	if assert.NoError(t, csvCodec.Unmarshal([]byte("field_a,field_b\nrow1a\nrow2a,row2b\n"), &obj)) {
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"field_a": "row1a"},
+			map[string]interface{}{"field_a": "row2a", "field_b": "row2b"},
+		}, obj)
+	}
+
+	err := csvCodec.Unmarshal([]byte("field_a\nrow1a,row1b\n"), &obj)
+
+	if assert.IsType(t, &RaggedRowError{}, err) {
+		assert.Equal(t, 1, err.(*RaggedRowError).Fields)
+		assert.Equal(t, 2, err.(*RaggedRowError).Values)
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	csvCodec := new(CsvCodec)
+	raw := []byte("field_a\nrow1a\nrow2a\n")
+
+	var object map[string]interface{}
+	assert.IsType(t, &InvalidUnmarshalError{}, csvCodec.Unmarshal(raw, object))
+
+	// many rows cannot go into a map
+	assert.IsType(t, &UnmarshalTypeError{}, csvCodec.Unmarshal(raw, &object))
+
+	var text string
+	assert.IsType(t, &UnmarshalTypeError{}, csvCodec.Unmarshal([]byte("field_a\nrow1a\n"), &text))
+
+}
+
+func TestMarshal_UnsupportedRow(t *testing.T) {
+
+	csvCodec := new(CsvCodec)
+
+	_, err := csvCodec.Marshal([]interface{}{map[string]interface{}{"name": "Mat"}, "Tyler"}, nil)
+	assert.IsType(t, &UnsupportedTypeError{}, err)
+
+	data, err := csvCodec.Marshal([]interface{}{objx.MSI("name", "Mat")}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "name\n\"\"\"Mat\"\"\"\n", string(data))
+	}
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	f.Add([]byte("field_a,field_b,field_c\nrow1a,row1b,row1c\n"))
+	f.Add([]byte("field_a,field_b\nrow1a\nrow2a,row2b,row2c\n"))
+	f.Add([]byte("name,obj\n\"Mat\",\"{\"\"age\"\":30}\"\n"))
+	f.Add([]byte("\"unterminated\n"))
+
+	csvCodec := new(CsvCodec)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		csvCodec.Unmarshal(data, &object)
+	})
+
+}
+
 func TestUnmarshal_SingleObject_WithNoEndLinefeed(t *testing.T) {
 
 	raw := "field_a,field_b,field_c\nrow1a,row1b,row1c"
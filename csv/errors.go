@@ -2,6 +2,7 @@ package csv
 
 import (
 	"reflect"
+	"strconv"
 )
 
 // An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
@@ -20,3 +21,38 @@ func (e *InvalidUnmarshalError) Error() string {
 	}
 	return "codecs: csv: Unmarshal(nil " + e.Type.String() + ")"
 }
+
+// An UnsupportedTypeError describes an item passed to Marshal that cannot be
+// encoded as a CSV row.  Only maps can be encoded as rows.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "codecs: csv: Marshal(nil row)"
+	}
+	return "codecs: csv: Marshal(" + e.Type.String() + "): only maps can be encoded as rows"
+}
+
+// An UnmarshalTypeError describes an object passed to Unmarshal that cannot hold
+// the CSV data, such as a map for data with more than one row.
+type UnmarshalTypeError struct {
+	Value reflect.Type
+	Type  reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "codecs: csv: cannot unmarshal " + e.Value.String() + " into " + e.Type.String()
+}
+
+// A RaggedRowError describes a row with more values than there are fields in
+// the header.
+type RaggedRowError struct {
+	Fields int
+	Values int
+}
+
+func (e *RaggedRowError) Error() string {
+	return "codecs: csv: row has " + strconv.Itoa(e.Values) + " values, but there are only " + strconv.Itoa(e.Fields) + " fields"
+}
@@ -0,0 +1,22 @@
+// A codec for handling HTML form (application/x-www-form-urlencoded) encoding and
+// decoding.
+//
+// Keys that appear more than once decode into arrays, and bracket notation
+// describes nested objects, so:
+//
+//	user[name]=Mat&user[email]=mat@example.com&tags=a&tags=b&ids[]=1
+//
+// decodes into:
+//
+//	map[string]interface{}{
+//		"user": map[string]interface{}{"name": "Mat", "email": "mat@example.com"},
+//		"tags": []interface{}{"a", "b"},
+//		"ids":  []interface{}{"1"},
+//	}
+//
+// Form data can also be unmarshalled into structs.  Fields are matched using the
+// name in their `form` tag, or otherwise their field name (ignoring case).  A tag
+// of "-" causes the field to be ignored.
+//
+// Marshalling works the other way, turning maps into encoded form bodies.
+package form
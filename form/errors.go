@@ -0,0 +1,58 @@
+package form
+
+import (
+	"reflect"
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: form: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: form: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: form: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// An UnsupportedTypeError describes a value passed to Marshal that cannot be
+// encoded as a form.  Only maps with string keys can be marshalled.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "codecs: form: Marshal(nil)"
+	}
+	return "codecs: form: Marshal(" + e.Type.String() + "): only maps can be encoded as forms"
+}
+
+// An InvalidKeyError describes a form key that cannot be decoded, such as a key
+// that is used both for a value and for a nested object.
+type InvalidKeyError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidKeyError) Error() string {
+	return "codecs: form: invalid key \"" + e.Key + "\": " + e.Reason
+}
+
+// An UnmarshalTypeError describes a form value that could not be converted into
+// the type of the field it was unmarshalled into.
+type UnmarshalTypeError struct {
+	Key   string
+	Value string
+	Type  reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "codecs: form: cannot unmarshal " + e.Value + " at \"" + e.Key + "\" into " + e.Type.String()
+}
@@ -0,0 +1,60 @@
+package form
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"net/url"
+	"reflect"
+)
+
+// FormCodec converts objects to and from HTML form (application/x-www-form-urlencoded)
+// bodies.
+type FormCodec struct{}
+
+// Marshal converts a map into an encoded form body.
+func (c *FormCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	values, err := MarshalValues(object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(values.Encode()), nil
+}
+
+// Unmarshal converts an encoded form body into an object.
+//
+// The obj must be a non-nil pointer to an interface{}, a map or a struct.
+func (c *FormCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	values, err := url.ParseQuery(string(data))
+
+	if err != nil {
+		return err
+	}
+
+	return UnmarshalValues(values, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *FormCodec) ContentType() string {
+	return constants.ContentTypeForm
+}
+
+// FileExtension returns the file extension for this codec.
+//
+// Forms have no file extension, so this is always "".
+func (c *FormCodec) FileExtension() string {
+	return ""
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *FormCodec) CanMarshalWithCallback() bool {
+	return false
+}
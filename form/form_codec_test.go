@@ -0,0 +1,236 @@
+package form
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/objx"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var codec FormCodec
+
+type signup struct {
+	Name     string
+	Age      int       `form:"age"`
+	Agree    bool      `form:"agree"`
+	Tags     []string  `form:"tags"`
+	Born     time.Time `form:"born"`
+	Password string    `form:"-"`
+	Address  struct {
+		City string `form:"city"`
+	} `form:"address"`
+	Score *float64 `form:"score"`
+}
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(FormCodec), "FormCodec")
+
+}
+
+func TestMarshal(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"name": "Mat Ryer",
+		"age":  30,
+		"tags": []interface{}{"a", "b"},
+		"user": map[string]interface{}{
+			"email": "mat@example.com",
+		},
+		"pets": []interface{}{map[string]interface{}{"name": "Dog"}},
+		"none": nil,
+	}
+
+	formData, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "age=30&name=Mat+Ryer&none=&pets%5B0%5D%5Bname%5D=Dog&tags=a&tags=b&user%5Bemail%5D=mat%40example.com", string(formData))
+	}
+
+}
+
+func TestMarshal_ObjxMap(t *testing.T) {
+
+	formData, err := codec.Marshal(objx.MSI("name", "Mat", "born", time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC)), nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "born=2013-03-20T12%3A00%3A00Z&name=Mat", string(formData))
+	}
+
+}
+
+func TestMarshal_Unsupported(t *testing.T) {
+
+	_, err := codec.Marshal([]interface{}{"a"}, nil)
+	assert.IsType(t, &UnsupportedTypeError{}, err)
+
+	_, err = codec.Marshal(nil, nil)
+	assert.IsType(t, &UnsupportedTypeError{}, err)
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	formString := "name=Mat+Ryer&tags=a&tags=b&ids%5B%5D=1&user%5Bname%5D=Mat&user%5Baddress%5D%5Bcity%5D=Boulder"
+	var object interface{}
+
+	if assert.NoError(t, codec.Unmarshal([]byte(formString), &object)) {
+		assert.Equal(t, map[string]interface{}{
+			"name": "Mat Ryer",
+			"tags": []interface{}{"a", "b"},
+			"ids":  []interface{}{"1"},
+			"user": map[string]interface{}{
+				"name":    "Mat",
+				"address": map[string]interface{}{"city": "Boulder"},
+			},
+		}, object)
+	}
+
+}
+
+func TestUnmarshal_Map(t *testing.T) {
+
+	var object objx.Map
+
+	if assert.NoError(t, codec.Unmarshal([]byte("name=Mat&user[name]=Tyler"), &object)) {
+		assert.Equal(t, "Mat", object.Get("name").Str())
+		assert.Equal(t, "Tyler", object.Get("user.name").Str())
+	}
+
+	var strings map[string]string
+
+	if assert.NoError(t, codec.Unmarshal([]byte("name=Mat&tags=a&tags=b"), &strings)) {
+		assert.Equal(t, map[string]string{"name": "Mat", "tags": "a"}, strings)
+	}
+
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+
+	formString := "Name=Mat&age=30&agree=on&tags=a&tags=b&born=2013-03-20T12%3A00%3A00Z&Password=secret&address%5Bcity%5D=Boulder&score=9.5"
+	var object signup
+
+	if assert.NoError(t, codec.Unmarshal([]byte(formString), &object)) {
+		assert.Equal(t, "Mat", object.Name)
+		assert.Equal(t, 30, object.Age)
+		assert.True(t, object.Agree)
+		assert.Equal(t, []string{"a", "b"}, object.Tags)
+		assert.True(t, time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC).Equal(object.Born))
+		assert.Equal(t, "", object.Password)
+		assert.Equal(t, "Boulder", object.Address.City)
+		if assert.NotNil(t, object.Score) {
+			assert.Equal(t, 9.5, *object.Score)
+		}
+	}
+
+	// empty fields are left alone
+	object = signup{Age: 5}
+
+	if assert.NoError(t, codec.Unmarshal([]byte("age=&name=Tyler"), &object)) {
+		assert.Equal(t, 5, object.Age)
+		assert.Equal(t, "Tyler", object.Name)
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var object interface{}
+	var typed signup
+
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal([]byte("name=Mat"), object))
+	assert.Error(t, codec.Unmarshal([]byte("name=%zz"), &object))
+
+	err := codec.Unmarshal([]byte("user=Mat&user[name]=Mat"), &object)
+
+	if assert.IsType(t, &InvalidKeyError{}, err) {
+		assert.Equal(t, "user[name]", err.(*InvalidKeyError).Key)
+	}
+
+	err = codec.Unmarshal([]byte("age=thirty"), &typed)
+
+	if assert.IsType(t, &UnmarshalTypeError{}, err) {
+		assert.Equal(t, "codecs: form: cannot unmarshal \"thirty\" at \"age\" into int", err.Error())
+	}
+
+	assert.IsType(t, &UnmarshalTypeError{}, codec.Unmarshal([]byte("address=Boulder"), &typed))
+	assert.IsType(t, &UnmarshalTypeError{}, codec.Unmarshal([]byte("name[first]=Mat"), &typed))
+
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"name": "Mat",
+		"tags": []interface{}{"a", "b"},
+		"user": map[string]interface{}{"email": "mat@example.com"},
+	}
+
+	formData, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+
+		var object map[string]interface{}
+
+		if assert.NoError(t, codec.Unmarshal(formData, &object)) {
+			assert.Equal(t, obj, object)
+		}
+
+	}
+
+}
+
+func TestValues(t *testing.T) {
+
+	var object map[string]interface{}
+
+	if assert.NoError(t, UnmarshalValues(url.Values{"user[name]": {"Mat"}}, &object)) {
+		assert.Equal(t, map[string]interface{}{"user": map[string]interface{}{"name": "Mat"}}, object)
+	}
+
+	values, err := MarshalValues(object)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, url.Values{"user[name]": {"Mat"}}, values)
+	}
+
+}
+
+func TestParseKey(t *testing.T) {
+
+	assert.Equal(t, []string{"name"}, parseKey("name"))
+	assert.Equal(t, []string{"user", "name"}, parseKey("user[name]"))
+	assert.Equal(t, []string{"user", "address", "city"}, parseKey("user[address][city]"))
+	assert.Equal(t, []string{"tags", ""}, parseKey("tags[]"))
+	assert.Equal(t, []string{"[name]"}, parseKey("[name]"))
+	assert.Equal(t, []string{"user[name"}, parseKey("user[name"))
+	assert.Equal(t, []string{"user[name]x]"}, parseKey("user[name]x]"))
+
+}
+
+func TestContentType(t *testing.T) {
+
+	assert.Equal(t, constants.ContentTypeForm, codec.ContentType())
+	assert.Equal(t, "", codec.FileExtension())
+	assert.False(t, codec.CanMarshalWithCallback())
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(FormCodec)
+
+	f.Add([]byte("name=Mat&age=30&tags[]=a&tags[]=b"))
+	f.Add([]byte("user[name]=Tyler&user[address][city]=London"))
+	f.Add([]byte("a=1&a[b]=2&[]=3&c[][]=4"))
+	f.Add([]byte("%zz=%"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
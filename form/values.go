@@ -0,0 +1,425 @@
+package form
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	typeTextMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	typeTextUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// UnmarshalValues unmarshals parsed form values into an object, in the same way
+// as FormCodec.Unmarshal.
+func UnmarshalValues(values url.Values, obj interface{}) error {
+
+	fields := make(map[string][]interface{}, len(values))
+	for key, keyValues := range values {
+		for _, value := range keyValues {
+			fields[key] = append(fields[key], value)
+		}
+	}
+
+	return UnmarshalFields(fields, obj)
+}
+
+// UnmarshalFields unmarshals fields into an object in the same way as
+// UnmarshalValues, except the values may be of any type, such as the file parts
+// of a multipart form.
+//
+// Values that are not strings can only be unmarshalled into an interface{}, or
+// a field of a type they are assignable to.
+func UnmarshalFields(fields map[string][]interface{}, obj interface{}) error {
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	object, err := fieldsToMap(fields)
+
+	if err != nil {
+		return err
+	}
+
+	return assign("", object, rv.Elem())
+}
+
+// MarshalValues converts a map into form values, in the same way as
+// FormCodec.Marshal.
+func MarshalValues(object interface{}) (url.Values, error) {
+
+	objectValue := reflect.ValueOf(object)
+	if objectValue.Kind() != reflect.Map || objectValue.Type().Key().Kind() != reflect.String {
+		return nil, &UnsupportedTypeError{reflect.TypeOf(object)}
+	}
+
+	values := make(url.Values)
+	addValues(values, "", objectValue)
+
+	return values, nil
+}
+
+// fieldsToMap converts fields into a map, using bracket notation in the keys to
+// build nested maps.
+func fieldsToMap(fields map[string][]interface{}) (map[string]interface{}, error) {
+
+	// sort the keys so errors are reported consistently
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	object := make(map[string]interface{})
+
+	for _, key := range keys {
+		if err := insert(object, key, parseKey(key), fields[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	return object, nil
+}
+
+// parseKey splits a key using bracket notation into its path, so "user[name]"
+// becomes ["user", "name"] and "tags[]" becomes ["tags", ""].
+//
+// Keys that do not use bracket notation correctly are not split.
+func parseKey(key string) []string {
+
+	open := strings.IndexByte(key, '[')
+	if open <= 0 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+
+	path := []string{key[:open]}
+
+	for rest := key[open:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return []string{key}
+		}
+		path = append(path, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	return path
+}
+
+// insert puts the values into the object at the path.
+func insert(object map[string]interface{}, key string, path []string, values []interface{}) error {
+
+	// a trailing "[]" means the values are always an array
+	arrayKey := len(path) > 1 && path[len(path)-1] == ""
+	if arrayKey {
+		path = path[:len(path)-1]
+	}
+
+	// walk to the map holding the value, making maps on the way
+	for _, segment := range path[:len(path)-1] {
+
+		if segment == "" {
+			return &InvalidKeyError{key, "[] can only be used at the end of a key"}
+		}
+
+		switch child := object[segment].(type) {
+		case nil:
+			nested := make(map[string]interface{})
+			object[segment] = nested
+			object = nested
+		case map[string]interface{}:
+			object = child
+		default:
+			return &InvalidKeyError{key, "\"" + segment + "\" is already used for a value"}
+		}
+
+	}
+
+	name := path[len(path)-1]
+
+	if name == "" {
+		return &InvalidKeyError{key, "[] can only be used at the end of a key"}
+	}
+	if _, exists := object[name]; exists {
+		return &InvalidKeyError{key, "\"" + name + "\" is already used"}
+	}
+
+	if len(values) == 1 && !arrayKey {
+		object[name] = values[0]
+		return nil
+	}
+
+	object[name] = append([]interface{}(nil), values...)
+
+	return nil
+}
+
+// assign puts the decoded value into the target, converting it into the type of
+// the target.
+func assign(key string, value interface{}, target reflect.Value) error {
+
+	if valueOf := reflect.ValueOf(value); valueOf.IsValid() && valueOf.Type().AssignableTo(target.Type()) {
+		target.Set(valueOf)
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return assign(key, value, target.Elem())
+	}
+
+	if target.Kind() == reflect.Interface && target.NumMethod() == 0 {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if target.CanAddr() && target.Addr().Type().Implements(typeTextUnmarshaler) {
+		text, err := scalar(key, value, target.Type())
+		if err != nil {
+			return err
+		}
+		return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch target.Kind() {
+	case reflect.Map:
+
+		object, ok := value.(map[string]interface{})
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			return &UnmarshalTypeError{key, describe(value), target.Type()}
+		}
+
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+
+		for name, item := range object {
+			itemValue := reflect.New(target.Type().Elem()).Elem()
+			if err := assign(joinKey(key, name), item, itemValue); err != nil {
+				return err
+			}
+			target.SetMapIndex(reflect.ValueOf(name).Convert(target.Type().Key()), itemValue)
+		}
+
+	case reflect.Struct:
+
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return &UnmarshalTypeError{key, describe(value), target.Type()}
+		}
+
+		targetType := target.Type()
+		for index := 0; index < targetType.NumField(); index++ {
+
+			field := targetType.Field(index)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			name, item, found := field.Tag.Get("form"), interface{}(nil), false
+			if name == "-" {
+				continue
+			} else if name != "" {
+				item, found = object[name]
+			} else {
+				for objectName, objectItem := range object {
+					if strings.EqualFold(objectName, field.Name) {
+						name, item, found = objectName, objectItem, true
+						break
+					}
+				}
+			}
+
+			if found {
+				if err := assign(joinKey(key, name), item, target.Field(index)); err != nil {
+					return err
+				}
+			}
+		}
+
+	case reflect.Slice:
+
+		var items []interface{}
+		switch typedValue := value.(type) {
+		case string:
+			items = []interface{}{typedValue}
+		case []interface{}:
+			items = typedValue
+		default:
+			return &UnmarshalTypeError{key, describe(value), target.Type()}
+		}
+
+		slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+		for index, item := range items {
+			if err := assign(key+"["+strconv.Itoa(index)+"]", item, slice.Index(index)); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+
+	default:
+
+		text, err := scalar(key, value, target.Type())
+		if err != nil {
+			return err
+		}
+
+		return setScalar(key, text, target)
+
+	}
+
+	return nil
+}
+
+// setScalar parses the text into the target.
+//
+// Empty text leaves the target unchanged, since browsers send empty fields as
+// empty values.
+func setScalar(key, text string, target reflect.Value) error {
+
+	if text == "" && target.Kind() != reflect.String {
+		return nil
+	}
+
+	var err error
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(text)
+	case reflect.Bool:
+		// checkboxes without a value attribute are sent as "on"
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil || text == "on" {
+			target.SetBool(b || text == "on")
+			err = nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(text, 10, target.Type().Bits()); err == nil {
+			target.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(text, 10, target.Type().Bits()); err == nil {
+			target.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(text, target.Type().Bits()); err == nil {
+			target.SetFloat(f)
+		}
+	default:
+		return &UnmarshalTypeError{key, strconv.Quote(text), target.Type()}
+	}
+
+	if err != nil {
+		return &UnmarshalTypeError{key, strconv.Quote(text), target.Type()}
+	}
+
+	return nil
+}
+
+// scalar gets the text of a value that is being assigned to a single value.  If
+// the value is an array, the first item is used.
+func scalar(key string, value interface{}, targetType reflect.Type) (string, error) {
+
+	if items, ok := value.([]interface{}); ok && len(items) > 0 {
+		value = items[0]
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return "", &UnmarshalTypeError{key, describe(value), targetType}
+	}
+
+	return text, nil
+}
+
+// describe gets a description of a decoded value for use in error messages.
+func describe(value interface{}) string {
+	switch typedValue := value.(type) {
+	case string:
+		return strconv.Quote(typedValue)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// addValues adds the value to the form values, using bracket notation for the keys
+// of nested maps.
+func addValues(values url.Values, key string, value reflect.Value) {
+
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			values.Add(key, "")
+			return
+		}
+		if value.Type().Implements(typeTextMarshaler) {
+			break
+		}
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		values.Add(key, "")
+		return
+	}
+
+	if value.Type().Implements(typeTextMarshaler) {
+		text, _ := value.Interface().(encoding.TextMarshaler).MarshalText()
+		values.Add(key, string(text))
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+
+		for _, mapKey := range value.MapKeys() {
+			addValues(values, joinKey(key, fmt.Sprint(mapKey.Interface())), value.MapIndex(mapKey))
+		}
+
+	case reflect.Slice, reflect.Array:
+
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			values.Add(key, fmt.Sprintf("%s", value.Interface()))
+			return
+		}
+
+		for index := 0; index < value.Len(); index++ {
+			item := value.Index(index)
+			for item.Kind() == reflect.Interface && !item.IsNil() {
+				item = item.Elem()
+			}
+			if item.Kind() == reflect.Map {
+				addValues(values, key+"["+strconv.Itoa(index)+"]", item)
+			} else {
+				addValues(values, key, item)
+			}
+		}
+
+	default:
+		values.Add(key, fmt.Sprint(value.Interface()))
+	}
+
+}
+
+// joinKey adds a name to a key using bracket notation.
+func joinKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "[" + name + "]"
+}
@@ -0,0 +1,93 @@
+package codecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Encode marshals the object with the codec.
+//
+// It is the type-safe equivalent of calling Marshal on the codec.
+func Encode[T any](codec Codec, object T, options map[string]interface{}) ([]byte, error) {
+	return codec.Marshal(object, options)
+}
+
+// Decode unmarshals the data with the codec into a new value of type T.
+//
+// If T is a pointer type, such as a generated protobuf message, a new value is
+// allocated for it to point to, so
+//
+//	message, err := codecs.Decode[*pb.Person](codec, data)
+//
+// unmarshals into a new pb.Person.
+func Decode[T any](codec Codec, data []byte) (T, error) {
+
+	var object T
+	var target interface{} = &object
+
+	if objectType := typeOf[T](); objectType.Kind() == reflect.Ptr {
+		object = reflect.New(objectType.Elem()).Interface().(T)
+		target = object
+	}
+
+	if err := codec.Unmarshal(data, target); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return object, nil
+}
+
+// TypedCodec wraps a codec to marshal and unmarshal values of type T only.
+type TypedCodec[T any] struct {
+
+	// Codec is the codec that does the work.
+	Codec Codec
+}
+
+// Encode marshals the object with the codec.
+func (c *TypedCodec[T]) Encode(object T, options map[string]interface{}) ([]byte, error) {
+	return Encode(c.Codec, object, options)
+}
+
+// Decode unmarshals the data with the codec into a new value of type T.
+func (c *TypedCodec[T]) Decode(data []byte) (T, error) {
+	return Decode[T](c.Codec, data)
+}
+
+// Registry holds the codec to use for each Go type.  It is safe for concurrent
+// use.
+type Registry struct {
+	mutex  sync.RWMutex
+	codecs map[reflect.Type]Codec
+}
+
+// NewRegistry makes a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{codecs: make(map[reflect.Type]Codec)}
+}
+
+// Register sets the codec to use for values of type T in the registry,
+// replacing any codec already registered for T.
+func Register[T any](registry *Registry, codec Codec) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.codecs[typeOf[T]()] = codec
+}
+
+// CodecFor gets a TypedCodec for values of type T from the registry, or false if
+// no codec is registered for T.
+func CodecFor[T any](registry *Registry) (*TypedCodec[T], bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	codec, ok := registry.codecs[typeOf[T]()]
+	if !ok {
+		return nil, false
+	}
+	return &TypedCodec[T]{codec}, true
+}
+
+// typeOf gets the reflect.Type of T, including interface types.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
@@ -0,0 +1,216 @@
+package codecs_test
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/form"
+	"github.com/stretchr/codecs/html"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/protobuf"
+	"github.com/stretchr/codecs/services"
+	"github.com/stretchr/codecs/text"
+	"github.com/stretchr/codecs/yaml"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"sync"
+	"testing"
+)
+
+type person struct {
+	Name string `json:"name" yaml:"name" toml:"name" cbor:"name" form:"name"`
+	City string `json:"city" yaml:"city" toml:"city" cbor:"city" form:"city"`
+}
+
+func TestEncodeDecode_AllCodecs(t *testing.T) {
+
+	obj := map[string]interface{}{"name": "Mat", "city": "Boulder"}
+	options := map[string]interface{}{constants.OptionKeyClientCallback: "callback"}
+
+	for _, codec := range services.DefaultCodecs {
+
+		switch codec.(type) {
+		case *protobuf.ProtobufCodec, *text.TextCodec, *html.HtmlCodec:
+			// these do not round-trip maps, and are tested separately
+			continue
+		}
+
+		data, err := codecs.Encode(codec, obj, options)
+
+		if assert.NoError(t, err, codec.ContentType()) {
+
+			object, err := codecs.Decode[map[string]interface{}](codec, data)
+
+			if assert.NoError(t, err, codec.ContentType()) {
+				assert.Equal(t, obj, object, codec.ContentType())
+			}
+
+		}
+
+	}
+
+}
+
+func TestEncodeDecode_Struct(t *testing.T) {
+
+	obj := person{"Mat", "Boulder"}
+
+	for _, codec := range services.DefaultCodecs {
+
+		switch codec.ContentType() {
+		case constants.ContentTypeJSON, constants.ContentTypeYAML, constants.ContentTypeTOML, constants.ContentTypeCBOR:
+		default:
+			continue
+		}
+
+		data, err := codecs.Encode(codec, obj, nil)
+
+		if assert.NoError(t, err, codec.ContentType()) {
+
+			object, err := codecs.Decode[person](codec, data)
+
+			if assert.NoError(t, err, codec.ContentType()) {
+				assert.Equal(t, obj, object, codec.ContentType())
+			}
+
+		}
+
+	}
+
+	// forms marshal maps, but unmarshal into structs
+	object, err := codecs.Decode[person](new(form.FormCodec), []byte("name=Mat&city=Boulder"))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, obj, object)
+	}
+
+}
+
+func TestEncodeDecode_Protobuf(t *testing.T) {
+
+	codec := new(protobuf.ProtobufCodec)
+	obj, _ := structpb.NewStruct(map[string]interface{}{"name": "Mat"})
+
+	data, err := codecs.Encode(codec, obj, nil)
+
+	if assert.NoError(t, err) {
+
+		// the pointer is allocated by Decode
+		object, err := codecs.Decode[*structpb.Struct](codec, data)
+
+		if assert.NoError(t, err) && assert.NotNil(t, object) {
+			assert.True(t, proto.Equal(obj, object))
+		}
+
+	}
+
+}
+
+func TestEncodeDecode_Text(t *testing.T) {
+
+	data, err := codecs.Encode(new(text.TextCodec), "Hello", nil)
+
+	if assert.NoError(t, err) {
+
+		object, err := codecs.Decode[string](new(text.TextCodec), data)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, "Hello", object)
+		}
+
+	}
+
+	data, err = codecs.Encode(new(html.HtmlCodec), []string{"a"}, nil)
+
+	if assert.NoError(t, err) {
+		_, err := codecs.Decode[[]string](new(html.HtmlCodec), data)
+		assert.Error(t, err)
+	}
+
+}
+
+func TestDecode_Error(t *testing.T) {
+
+	object, err := codecs.Decode[person](new(json.JsonCodec), []byte(`{"name":`))
+
+	assert.Error(t, err)
+	assert.Equal(t, person{}, object)
+
+	pointer, err := codecs.Decode[*person](new(json.JsonCodec), []byte(`{"name":"Mat"}`))
+
+	if assert.NoError(t, err) && assert.NotNil(t, pointer) {
+		assert.Equal(t, "Mat", pointer.Name)
+	}
+
+}
+
+func TestRegistry(t *testing.T) {
+
+	registry := codecs.NewRegistry()
+
+	_, ok := codecs.CodecFor[person](registry)
+	assert.False(t, ok)
+
+	codecs.Register[person](registry, new(json.JsonCodec))
+	codecs.Register[map[string]interface{}](registry, new(yaml.YamlCodec))
+
+	personCodec, ok := codecs.CodecFor[person](registry)
+
+	if assert.True(t, ok) {
+
+		data, err := personCodec.Encode(person{"Mat", "Boulder"}, nil)
+
+		if assert.NoError(t, err) {
+
+			assert.Equal(t, `{"name":"Mat","city":"Boulder"}`, string(data))
+
+			object, err := personCodec.Decode(data)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, person{"Mat", "Boulder"}, object)
+			}
+
+		}
+
+	}
+
+	mapCodec, ok := codecs.CodecFor[map[string]interface{}](registry)
+
+	if assert.True(t, ok) {
+		assert.Equal(t, constants.ContentTypeYAML, mapCodec.Codec.ContentType())
+	}
+
+	// pointers are different types
+	_, ok = codecs.CodecFor[*person](registry)
+	assert.False(t, ok)
+
+	// registering again replaces the codec
+	codecs.Register[person](registry, new(yaml.YamlCodec))
+	personCodec, _ = codecs.CodecFor[person](registry)
+	assert.Equal(t, constants.ContentTypeYAML, personCodec.Codec.ContentType())
+
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+
+	registry := codecs.NewRegistry()
+	var waitGroup sync.WaitGroup
+
+	for index := 0; index < 10; index++ {
+		waitGroup.Add(2)
+		go func() {
+			defer waitGroup.Done()
+			codecs.Register[person](registry, new(json.JsonCodec))
+		}()
+		go func() {
+			defer waitGroup.Done()
+			codecs.CodecFor[person](registry)
+		}()
+	}
+
+	waitGroup.Wait()
+
+	_, ok := codecs.CodecFor[person](registry)
+	assert.True(t, ok)
+
+}
@@ -0,0 +1,9 @@
+// A codec for rendering data as HTML using html/template.
+//
+// The public data is rendered through a template chosen using the OptionTemplate
+// option, or the codec's DefaultTemplate, so the same handler output can be
+// negotiated into a page people can read.  Without templates, the data is shown
+// as indented JSON.
+//
+// HTML cannot be unmarshalled.
+package html
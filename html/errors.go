@@ -0,0 +1,18 @@
+package html
+
+import (
+	"errors"
+)
+
+// ErrorUnmarshalNotSupported is the error for when Unmarshal is called but not supported.
+var ErrorUnmarshalNotSupported = errors.New("codecs: html: unmarshalling HTML is not supported")
+
+// A TemplateNotFoundError describes a template name passed in the OptionTemplate
+// option, or set as the DefaultTemplate, that is not one of the codec's templates.
+type TemplateNotFoundError struct {
+	Name string
+}
+
+func (e *TemplateNotFoundError) Error() string {
+	return "codecs: html: no template named \"" + e.Name + "\""
+}
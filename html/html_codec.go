@@ -0,0 +1,100 @@
+package html
+
+import (
+	"bytes"
+	jsonEncoding "encoding/json"
+	"github.com/stretchr/codecs/constants"
+	"html/template"
+)
+
+const (
+	// OptionTemplate is the option key for the name of the template to render the
+	// data with.  It overrides the codec's DefaultTemplate.
+	OptionTemplate string = "options.html.template"
+)
+
+var validHtmlFileExtensions = []string{
+	constants.FileExtensionHTML,
+	constants.FileExtensionHTM,
+}
+
+// fallbackTemplate renders data as indented JSON, and is used when the codec has
+// no templates.
+var fallbackTemplate = template.Must(template.New("").Funcs(template.FuncMap{
+	"json": func(object interface{}) (string, error) {
+		data, err := jsonEncoding.MarshalIndent(object, "", "  ")
+		return string(data), err
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<body>
+<pre>{{json .}}</pre>
+</body>
+</html>
+`))
+
+// HtmlCodec converts objects to HTML by rendering them through a template.
+type HtmlCodec struct {
+
+	// Templates holds the templates that data can be rendered with.  If nil, the
+	// data is rendered as indented JSON.
+	Templates *template.Template
+
+	// DefaultTemplate is the name of the template used when the OptionTemplate
+	// option is not given.  If empty, Templates itself is used.
+	DefaultTemplate string
+}
+
+// Marshal converts an object to HTML.
+func (c *HtmlCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	name := c.DefaultTemplate
+	if optionName, ok := options[OptionTemplate].(string); ok {
+		name = optionName
+	}
+
+	tmpl := fallbackTemplate
+	if c.Templates != nil {
+		tmpl = c.Templates
+		if name != "" {
+			if tmpl = c.Templates.Lookup(name); tmpl == nil {
+				return nil, &TemplateNotFoundError{name}
+			}
+		}
+	} else if name != "" {
+		return nil, &TemplateNotFoundError{name}
+	}
+
+	byteBuffer := new(bytes.Buffer)
+
+	if err := tmpl.Execute(byteBuffer, object); err != nil {
+		return nil, err
+	}
+
+	return byteBuffer.Bytes(), nil
+}
+
+// Unmarshal is not supported, and always returns ErrorUnmarshalNotSupported.
+func (c *HtmlCodec) Unmarshal(data []byte, obj interface{}) error {
+	return ErrorUnmarshalNotSupported
+}
+
+// ContentType returns the content type for this codec.
+func (c *HtmlCodec) ContentType() string {
+	return constants.ContentTypeHTML
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *HtmlCodec) FileExtension() string {
+	return constants.FileExtensionHTML
+}
+
+// FileExtensions returns all of the file extensions by which this codec is represented.
+func (c *HtmlCodec) FileExtensions() []string {
+	return validHtmlFileExtensions
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *HtmlCodec) CanMarshalWithCallback() bool {
+	return false
+}
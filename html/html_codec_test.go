@@ -0,0 +1,86 @@
+package html
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"html/template"
+	"testing"
+)
+
+var templates = template.Must(template.New("page").Parse(`<h1>{{.name}}</h1>`)).New("list")
+
+func init() {
+	template.Must(templates.Parse(`<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>`))
+}
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(HtmlCodec), "HtmlCodec")
+	assert.Implements(t, (*codecs.FileExtensionsCodec)(nil), new(HtmlCodec), "HtmlCodec")
+
+}
+
+func TestMarshal(t *testing.T) {
+
+	codec := &HtmlCodec{Templates: templates, DefaultTemplate: "page"}
+
+	data, err := codec.Marshal(map[string]interface{}{"name": "<Mat>"}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<h1>&lt;Mat&gt;</h1>", string(data))
+	}
+
+	data, err = codec.Marshal([]interface{}{"a", "b"}, map[string]interface{}{OptionTemplate: "list"})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<ul><li>a</li><li>b</li></ul>", string(data))
+	}
+
+}
+
+func TestMarshal_TemplateNotFound(t *testing.T) {
+
+	codec := &HtmlCodec{Templates: templates}
+
+	_, err := codec.Marshal(nil, map[string]interface{}{OptionTemplate: "missing"})
+
+	if assert.IsType(t, &TemplateNotFoundError{}, err) {
+		assert.Equal(t, "codecs: html: no template named \"missing\"", err.Error())
+	}
+
+	_, err = new(HtmlCodec).Marshal(nil, map[string]interface{}{OptionTemplate: "page"})
+	assert.IsType(t, &TemplateNotFoundError{}, err)
+
+}
+
+func TestMarshal_NoTemplates(t *testing.T) {
+
+	codec := new(HtmlCodec)
+
+	data, err := codec.Marshal(map[string]interface{}{"name": "<Mat>"}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(data), "<!DOCTYPE html>")
+		assert.Contains(t, string(data), "<pre>{\n  &#34;name&#34;: &#34;\\u003cMat\\u003e&#34;\n}</pre>")
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	var object interface{}
+	assert.Equal(t, ErrorUnmarshalNotSupported, new(HtmlCodec).Unmarshal([]byte("<h1>Mat</h1>"), &object))
+
+}
+
+func TestContentType(t *testing.T) {
+
+	codec := new(HtmlCodec)
+
+	assert.Equal(t, constants.ContentTypeHTML, codec.ContentType())
+	assert.Equal(t, constants.FileExtensionHTML, codec.FileExtension())
+	assert.Equal(t, []string{".html", ".htm"}, codec.FileExtensions())
+	assert.False(t, codec.CanMarshalWithCallback())
+
+}
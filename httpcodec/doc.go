@@ -0,0 +1,27 @@
+// Helpers that connect codecs to net/http handlers.
+//
+// Respond negotiates a codec from the request (using its Accept header, the
+// extension of its path and its callback query parameter), and writes the object
+// to the response.  Decode reads the request body into an object using the codec
+// for its Content-Type.
+//
+//    func handler(w http.ResponseWriter, r *http.Request) {
+//
+//        var person Person
+//        if err := httpcodec.Decode(r, &person); err != nil {
+//            httpcodec.RespondError(w, r, err)
+//            return
+//        }
+//
+//        httpcodec.Respond(w, r, http.StatusOK, person)
+//
+//    }
+//
+// Middleware negotiates the codecs once per request, rejecting requests that
+// cannot be handled before they reach the handler, and limits the size of
+// request bodies.
+//
+//    helper := &httpcodec.Helper{CodecService: services.NewWebCodecService(), MaxBodySize: 1 << 20}
+//    http.Handle("/people", helper.Middleware(http.HandlerFunc(handler)))
+//
+package httpcodec
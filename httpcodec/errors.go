@@ -0,0 +1,63 @@
+package httpcodec
+
+import (
+	"github.com/stretchr/codecs"
+	"net/http"
+	"strconv"
+)
+
+// DecodeError is the error for when a request body cannot be read or unmarshalled.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "codecs: httpcodec: cannot decode request body: " + e.Err.Error()
+}
+
+// Unwrap gets the error that caused the request body not to be decoded.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ProblemDetails gets the ProblemDetails describing this error as a
+// 400 Bad Request problem.
+func (e *DecodeError) ProblemDetails() *codecs.ProblemDetails {
+	return codecs.NewProblemDetails(http.StatusBadRequest, e.Err.Error())
+}
+
+// NotAcceptableError is the error for when no installed codec can produce any of
+// the content types in a request's Accept header.
+type NotAcceptableError struct {
+	Accept string
+}
+
+func (e *NotAcceptableError) Error() string {
+	return "codecs: httpcodec: no codec can respond with " + e.Accept
+}
+
+// ProblemDetails gets the ProblemDetails describing this error as a
+// 406 Not Acceptable problem.
+func (e *NotAcceptableError) ProblemDetails() *codecs.ProblemDetails {
+	problem := codecs.NewProblemDetails(http.StatusNotAcceptable, e.Error())
+	problem.Extensions = map[string]interface{}{"accept": e.Accept}
+	return problem
+}
+
+// RequestTooLargeError is the error for when a request body is larger than the
+// MaxBodySize of a Helper.
+type RequestTooLargeError struct {
+	Limit int64
+}
+
+func (e *RequestTooLargeError) Error() string {
+	return "codecs: httpcodec: request body is larger than " + strconv.FormatInt(e.Limit, 10) + " bytes"
+}
+
+// ProblemDetails gets the ProblemDetails describing this error as a
+// 413 Request Entity Too Large problem.
+func (e *RequestTooLargeError) ProblemDetails() *codecs.ProblemDetails {
+	problem := codecs.NewProblemDetails(http.StatusRequestEntityTooLarge, e.Error())
+	problem.Extensions = map[string]interface{}{"limit": e.Limit}
+	return problem
+}
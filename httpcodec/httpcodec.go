@@ -0,0 +1,495 @@
+package httpcodec
+
+import (
+	"errors"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/compression"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/jsonp"
+	"github.com/stretchr/codecs/services"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// CallbackParameter is the query parameter holding the name of the callback
+	// for responses that are marshalled with a callback, such as JSONP.
+	CallbackParameter string = "callback"
+
+	// ContextParameter is the query parameter holding the client context that is
+	// passed to the callback.
+	ContextParameter string = "context"
+)
+
+// encodingNegotiator is implemented by codec services that can compress
+// responses, such as services.WebCodecService.
+type encodingNegotiator interface {
+	NegotiateEncoding(result *services.NegotiationResult, acceptEncoding string) error
+}
+
+// pathParser is implemented by codec services that can split the extension of an
+// installed codec from a URL path, such as services.WebCodecService.
+type pathParser interface {
+	ParsePath(urlPath string) (resourcePath, extension string)
+}
+
+// decompressor is implemented by codec services that can decompress request
+// bodies, such as services.WebCodecService.
+type decompressor interface {
+	GetCodecWithEncoding(contentType, contentEncoding string) (codecs.Codec, error)
+}
+
+// Helper connects a CodecService to net/http requests and responses.
+type Helper struct {
+
+	// CodecService is the service used to get codecs.
+	CodecService services.CodecService
+
+	// MaxBodySize is the largest request body, in bytes, that Decode reads, both
+	// before and after it is decompressed.  If zero, there is no limit.
+	MaxBodySize int64
+}
+
+// DefaultHelper is the Helper used by the Respond, RespondError and Decode
+// functions.
+var DefaultHelper = &Helper{CodecService: services.NewWebCodecService()}
+
+// Respond writes the object to the response with the DefaultHelper.
+func Respond(w http.ResponseWriter, r *http.Request, status int, object interface{}) error {
+	return DefaultHelper.Respond(w, r, status, object)
+}
+
+// RespondError writes the problem details of the error to the response with the
+// DefaultHelper.
+func RespondError(w http.ResponseWriter, r *http.Request, err error) error {
+	return DefaultHelper.RespondError(w, r, err)
+}
+
+// Decode reads the request body into the object with the DefaultHelper.
+func Decode(r *http.Request, object interface{}) error {
+	return DefaultHelper.Decode(r, object)
+}
+
+// Respond marshals the object with the codec negotiated from the request, and
+// writes it to the response with the status.
+//
+// The codec is chosen by services.Negotiate, from the request's Accept header,
+// the extension of its path and whether it has a callback query parameter, and
+// the response headers describing the choice (such as Vary and
+// Content-Location) are set.  If the service can compress responses, the
+// response is compressed with the coding negotiated from the Accept-Encoding
+// header.  If the request passed through the Middleware, the codec it
+// negotiated is used.
+//
+// If no codec can produce a content type the Accept header accepts, or the
+// headers cannot be parsed, a 406 Not Acceptable problem is written instead
+// and the error is returned.  If the callback query parameter is not a valid
+// callback, a 400 Bad Request problem is written instead.
+//
+// If the object cannot be marshalled, nothing is written and the error is
+// returned.
+func (h *Helper) Respond(w http.ResponseWriter, r *http.Request, status int, object interface{}) error {
+
+	result, options, err := h.responseResult(r)
+	if err != nil {
+		h.respondNegotiationError(w, r, err)
+		return err
+	}
+
+	return h.write(w, r, status, result, h.resourcePath(r, result), object, options)
+}
+
+// RespondError writes the problem details of the error to the response, with
+// the status of the problem.  Problems are written as application/problem+json
+// or application/problem+xml when JSON or XML is negotiated, and as
+// application/problem+json when the negotiated codec cannot marshal them.
+func (h *Helper) RespondError(w http.ResponseWriter, r *http.Request, err error) error {
+
+	problem := codecs.ProblemDetailsFromError(err)
+	if problem == nil {
+		return nil
+	}
+
+	result, options, negotiateErr := h.responseResult(r)
+	if negotiateErr != nil {
+		h.respondNegotiationError(w, r, negotiateErr)
+		return negotiateErr
+	}
+
+	problemResult := h.problemResult(result)
+
+	data, marshalOptions, err := h.marshal(problemResult, problem, options)
+	if err != nil {
+		// some codecs, such as protobuf, cannot marshal problems
+		if problemResult = h.resultWithContentType(result, constants.ContentTypeProblemJSON); problemResult == nil {
+			return err
+		}
+		if data, marshalOptions, err = h.marshal(problemResult, problem, nil); err != nil {
+			return err
+		}
+	}
+
+	// problems describe this response, not the resource, so have no Content-Location
+	return h.send(w, r, problem.Status, problemResult, "", data, marshalOptions)
+}
+
+// Decode reads the request body, and unmarshals it into the object with the
+// codec for the request's Content-Type.
+//
+// The error for an unsupported Content-Type describes a 415 Unsupported Media
+// Type problem, the error for a body larger than the MaxBodySize is a
+// RequestTooLargeError, and the error for a body that cannot be read or
+// unmarshalled is a DecodeError, which describes a 400 Bad Request problem.  Any
+// of them can be written with RespondError.
+//
+// If the request passed through the Middleware, the codec it chose is used.
+func (h *Helper) Decode(r *http.Request, object interface{}) error {
+
+	codec, ok := RequestCodec(r)
+	if !ok {
+		var err error
+		if codec, err = h.requestCodec(r); err != nil {
+			return err
+		}
+	}
+
+	data, err := h.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.CodecService.UnmarshalWithCodec(codec, data, object); err != nil {
+		if errors.Is(err, compression.ErrorTooLarge) {
+			return &RequestTooLargeError{decompressedLimit(codec)}
+		}
+		return &DecodeError{err}
+	}
+
+	return nil
+}
+
+// requestCodec gets the codec for the request's Content-Type, which also
+// decompresses the body if the service can decompress request bodies.  The
+// MaxBodySize limits the decompressed body too, so it cannot be bypassed by
+// compressing the body.
+func (h *Helper) requestCodec(r *http.Request) (codecs.Codec, error) {
+
+	contentType := r.Header.Get("Content-Type")
+
+	if service, ok := h.CodecService.(decompressor); ok {
+		codec, err := service.GetCodecWithEncoding(contentType, r.Header.Get("Content-Encoding"))
+		if err != nil {
+			return nil, err
+		}
+		return limitDecompressed(codec, h.MaxBodySize), nil
+	}
+
+	return h.CodecService.GetCodec(contentType)
+}
+
+// limitDecompressed gets a copy of the codec that accepts no more than the limit
+// of decompressed data, if it decompresses data with a larger limit.
+func limitDecompressed(codec codecs.Codec, limit int64) codecs.Codec {
+
+	compressing, ok := codec.(*compression.CompressingCodec)
+	if !ok || limit <= 0 {
+		return codec
+	}
+
+	limited := *compressing
+	limited.Codec = limitDecompressed(compressing.Codec, limit)
+	if limit < decompressedLimit(compressing) || limited.MaxDecompressedSize < 0 {
+		limited.MaxDecompressedSize = limit
+	}
+
+	return &limited
+}
+
+// decompressedLimit gets the largest decompressed data the codec accepts, or
+// compression.NoLimit if there is no limit.
+func decompressedLimit(codec codecs.Codec) int64 {
+
+	compressing, ok := codec.(*compression.CompressingCodec)
+	if !ok {
+		return compression.NoLimit
+	}
+
+	switch {
+	case compressing.MaxDecompressedSize == 0:
+		return compression.DefaultMaxDecompressedSize
+	case compressing.MaxDecompressedSize < 0:
+		return compression.NoLimit
+	}
+
+	return compressing.MaxDecompressedSize
+}
+
+// readBody reads the request body, up to the MaxBodySize.
+func (h *Helper) readBody(r *http.Request) ([]byte, error) {
+
+	if r.Body == nil {
+		return nil, &DecodeError{io.EOF}
+	}
+
+	if h.MaxBodySize > 0 && r.ContentLength > h.MaxBodySize {
+		return nil, &RequestTooLargeError{h.MaxBodySize}
+	}
+
+	var reader io.Reader = r.Body
+	if h.MaxBodySize > 0 {
+		// read one byte more than the limit, to tell if it is exceeded
+		reader = io.LimitReader(r.Body, h.MaxBodySize+1)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, &RequestTooLargeError{maxBytesErr.Limit}
+		}
+		return nil, &DecodeError{err}
+	}
+
+	if h.MaxBodySize > 0 && int64(len(data)) > h.MaxBodySize {
+		return nil, &RequestTooLargeError{h.MaxBodySize}
+	}
+
+	return data, nil
+}
+
+// responseResult gets the result, and the options to marshal with, negotiated
+// by the Middleware, or negotiates them if the request did not pass through it.
+func (h *Helper) responseResult(r *http.Request) (*services.NegotiationResult, map[string]interface{}, error) {
+	if negotiated, ok := r.Context().Value(responseKey).(*negotiation); ok {
+		return negotiated.result, negotiated.options, nil
+	}
+	return h.negotiate(r)
+}
+
+// negotiate gets the result, and the options to marshal with, for responding to
+// the request.
+//
+// The error is a NotAcceptableError if the Accept header has no entry that an
+// installed codec can produce (so the first codec would be used), and a
+// jsonp.InvalidCallbackError if the codec would marshal with a callback that is
+// not valid.  Codecs chosen by the extension of the path or a callback are
+// acceptable whatever the Accept header, since the client asked for them.
+func (h *Helper) negotiate(r *http.Request) (*services.NegotiationResult, map[string]interface{}, error) {
+
+	query := r.URL.Query()
+	callback := query.Get(CallbackParameter)
+	accept := r.Header.Get("Accept")
+
+	_, extension := h.parsePath(r.URL.Path)
+
+	result, err := services.Negotiate(h.CodecService, accept, extension, callback != "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if accept != "" && result.Source == services.SourceDefault {
+		return nil, nil, &NotAcceptableError{accept}
+	}
+
+	var options map[string]interface{}
+	if callback != "" && result.Codec.CanMarshalWithCallback() {
+		if !jsonp.IsValidCallback(callback) {
+			return nil, nil, &jsonp.InvalidCallbackError{Callback: callback}
+		}
+		options = map[string]interface{}{constants.OptionKeyClientCallback: callback}
+		if clientContext, ok := query[ContextParameter]; ok && len(clientContext) > 0 {
+			options[constants.OptionKeyClientContext] = clientContext[0]
+		}
+	}
+
+	if service, ok := h.CodecService.(encodingNegotiator); ok {
+		if err := service.NegotiateEncoding(result, r.Header.Get("Accept-Encoding")); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return result, options, nil
+}
+
+// write marshals the object with the negotiated codec, and writes it to the
+// response with the headers describing the result.
+func (h *Helper) write(w http.ResponseWriter, r *http.Request, status int, result *services.NegotiationResult, resourcePath string, object interface{}, options map[string]interface{}) error {
+
+	data, marshalOptions, err := h.marshal(result, object, options)
+	if err != nil {
+		return err
+	}
+
+	return h.send(w, r, status, result, resourcePath, data, marshalOptions)
+}
+
+// marshal marshals the object with the negotiated codec, and gets the options
+// it was marshalled with, which can hold the content type of the data.
+func (h *Helper) marshal(result *services.NegotiationResult, object interface{}, options map[string]interface{}) ([]byte, map[string]interface{}, error) {
+
+	// copy the options, so codecs can give the content type of this response
+	// without changing the negotiated options
+	marshalOptions := make(map[string]interface{}, len(options)+1)
+	for key, value := range options {
+		marshalOptions[key] = value
+	}
+
+	data, err := h.CodecService.MarshalWithCodec(result.Codec, object, marshalOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, marshalOptions, nil
+}
+
+// send writes the marshalled data to the response with the headers describing
+// the result.  The Vary tokens are added to any the response already has, and
+// the other headers replace existing ones.
+func (h *Helper) send(w http.ResponseWriter, r *http.Request, status int, result *services.NegotiationResult, resourcePath string, data []byte, marshalOptions map[string]interface{}) error {
+
+	header := w.Header()
+	for name, values := range result.Header(resourcePath, marshalOptions) {
+		if name == "Vary" {
+			addVary(header, values)
+			continue
+		}
+		header.Set(name, values[0])
+	}
+	header.Set("Content-Length", strconv.Itoa(len(data)))
+
+	w.WriteHeader(status)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	_, err := w.Write(data)
+	return err
+}
+
+// addVary adds the tokens of the Vary values to the Vary header, keeping the
+// tokens it already has and skipping any it already lists, in any case.
+func addVary(header http.Header, values []string) {
+
+	var tokens []string
+	listed := make(map[string]bool)
+
+	for _, value := range append(header.Values("Vary"), values...) {
+		for _, token := range strings.Split(value, ",") {
+			token = strings.TrimSpace(token)
+			if token == "" || listed[strings.ToLower(token)] {
+				continue
+			}
+			listed[strings.ToLower(token)] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	if len(tokens) > 0 {
+		header.Set("Vary", strings.Join(tokens, ", "))
+	}
+}
+
+// respondNegotiationError writes the problem for the error negotiating the
+// response with the first codec, since no codec acceptable to the client could
+// be negotiated.  Errors that are not problems are written as a 406 Not
+// Acceptable problem.
+func (h *Helper) respondNegotiationError(w http.ResponseWriter, r *http.Request, err error) {
+
+	problem := codecs.ProblemDetailsFromError(err)
+	if problem.Status == http.StatusInternalServerError {
+		problem = codecs.NewProblemDetails(http.StatusNotAcceptable, err.Error())
+	}
+
+	result, fallbackErr := services.Negotiate(h.CodecService, "", "", false)
+	if fallbackErr != nil {
+		http.Error(w, problem.Error(), problem.Status)
+		return
+	}
+
+	if service, ok := h.CodecService.(encodingNegotiator); ok {
+		// the identity coding is always available
+		service.NegotiateEncoding(result, "")
+	}
+
+	if writeErr := h.write(w, r, problem.Status, h.problemResult(result), "", problem, nil); writeErr != nil {
+		http.Error(w, problem.Error(), problem.Status)
+	}
+}
+
+// problemResult gets the result for writing problem details with the negotiated
+// codec, using the problem content type of codecs that support
+// application/problem+json or application/problem+xml.
+func (h *Helper) problemResult(result *services.NegotiationResult) *services.NegotiationResult {
+
+	codec := result.Codec
+	if encoder, ok := codec.(*compression.CompressingCodec); ok {
+		codec = encoder.Codec
+	}
+
+	matcher, ok := codec.(codecs.ContentTypeMatcherCodec)
+	if !ok {
+		return result
+	}
+
+	for _, problemType := range []string{constants.ContentTypeProblemJSON, constants.ContentTypeProblemXML} {
+		if matcher.ContentTypeSupported(problemType) {
+			if problemResult := h.resultWithContentType(result, problemType); problemResult != nil {
+				return problemResult
+			}
+		}
+	}
+
+	return result
+}
+
+// resultWithContentType gets a copy of the result that responds with the codec
+// for the content type, compressed in the same way, or nil if no installed codec
+// responds with it.
+func (h *Helper) resultWithContentType(result *services.NegotiationResult, contentType string) *services.NegotiationResult {
+
+	codec, err := h.CodecService.GetCodecForResponding(contentType, "", false)
+	if err != nil || codec.ContentType() != contentType {
+		return nil
+	}
+
+	if encoder, ok := result.Codec.(*compression.CompressingCodec); ok {
+		codec = &compression.CompressingCodec{Codec: codec, Coding: encoder.Coding}
+	}
+
+	contentTypeResult := *result
+	contentTypeResult.Codec = codec
+	contentTypeResult.MediaType = contentType
+	contentTypeResult.Parameters = nil
+
+	return &contentTypeResult
+}
+
+// resourcePath gets the path of the resource the request is for, without the
+// extension that chose the codec, followed by the query if there is one.
+func (h *Helper) resourcePath(r *http.Request, result *services.NegotiationResult) string {
+	resourcePath := r.URL.Path
+	if result.Source == services.SourceExtension {
+		resourcePath, _ = h.parsePath(r.URL.Path)
+	}
+	if r.URL.RawQuery != "" {
+		return resourcePath + "?" + r.URL.RawQuery
+	}
+	return resourcePath
+}
+
+// parsePath splits the URL path into the path of the resource and the extension
+// of a codec, with the service's ParsePath if it has one.  Otherwise, the last
+// extension of the path is used.
+func (h *Helper) parsePath(urlPath string) (resourcePath, extension string) {
+
+	if parser, ok := h.CodecService.(pathParser); ok {
+		return parser.ParsePath(urlPath)
+	}
+
+	extension = path.Ext(urlPath)
+	return strings.TrimSuffix(urlPath, extension), extension
+}
@@ -0,0 +1,408 @@
+package httpcodec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/jsonp"
+	"github.com/stretchr/codecs/services"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type person struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestRespond(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept, Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "/people/1.json", w.Header().Get("Content-Location"))
+		assert.Equal(t, `{"name":"Mat"}`, w.Body.String())
+	}
+
+}
+
+func TestRespond_ExistingHeaders(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	w.Header().Set("Vary", "Origin, accept")
+	w.Header().Add("Vary", "Cookie")
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if assert.NoError(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, []string{"Origin, accept, Cookie, Accept-Encoding"}, w.Header().Values("Vary"))
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+		assert.Equal(t, "/people/1.json", w.Header().Get("Content-Location"))
+	}
+
+}
+
+func TestRespond_Extension(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1.xml", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(w, r, http.StatusCreated, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "text/xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Equal(t, "/people/1.xml", w.Header().Get("Content-Location"))
+		assert.Contains(t, w.Body.String(), "<name>")
+	}
+
+}
+
+func TestRespond_FileExtensions(t *testing.T) {
+
+	for urlPath, location := range map[string]string{
+		"/people/1.yml":      "/people/1.yaml",
+		"/people/1.yaml":     "/people/1.yaml",
+		"/v1.2/people":       "/v1.2/people.json",
+		"/":                  "",
+		"/users/":            "",
+		"/users.json":        "/users.json",
+		"/people?page=2":     "/people.json?page=2",
+		"/people.yml?page=2": "/people.yaml?page=2",
+	} {
+
+		r := httptest.NewRequest("GET", urlPath, nil)
+		w := httptest.NewRecorder()
+
+		if assert.NoError(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"}), urlPath) {
+			assert.Equal(t, location, w.Header().Get("Content-Location"), urlPath)
+		}
+
+	}
+
+}
+
+func TestRespond_Callback(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1?callback=show&context=abc", nil)
+	w := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, "text/javascript; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "", w.Header().Get("Content-Location"))
+		assert.Contains(t, w.Body.String(), `show({"name":"Mat"},"abc");`)
+	}
+
+}
+
+func TestRespond_Binary(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1", nil)
+	r.Header.Set("Accept", constants.ContentTypeMsgpack)
+	w := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, constants.ContentTypeMsgpack, w.Header().Get("Content-Type"))
+	}
+
+}
+
+func TestRespond_Multipart(t *testing.T) {
+
+	var boundaries []string
+
+	for i := 0; i < 2; i++ {
+
+		r := httptest.NewRequest("GET", "/people/1", nil)
+		r.Header.Set("Accept", constants.ContentTypeMultipart)
+		w := httptest.NewRecorder()
+
+		if assert.NoError(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+
+			mediaType, parameters, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, constants.ContentTypeMultipart, mediaType)
+				assert.True(t, strings.HasPrefix(w.Body.String(), "--"+parameters["boundary"]+"\r\n"))
+				boundaries = append(boundaries, parameters["boundary"])
+			}
+
+		}
+
+	}
+
+	// each response has its own boundary
+	if assert.Equal(t, 2, len(boundaries)) {
+		assert.NotEqual(t, boundaries[0], boundaries[1])
+	}
+
+}
+
+func TestRespond_Compressed(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+		reader, err := gzip.NewReader(w.Body)
+
+		if assert.NoError(t, err) {
+			data, _ := io.ReadAll(reader)
+			assert.Equal(t, `{"name":"Mat"}`, string(data))
+		}
+
+	}
+
+}
+
+func TestRespond_NotAcceptable(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1", nil)
+	r.Header.Set("Accept-Encoding", "*;q=0")
+	w := httptest.NewRecorder()
+
+	assert.IsType(t, &services.EncodingNotAcceptableError{}, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"}))
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"status":406`)
+
+	r = httptest.NewRequest("GET", "/people/1", nil)
+	r.Header.Set("Accept", "application/json;q=high")
+	w = httptest.NewRecorder()
+
+	assert.Error(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"}))
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+
+	// no installed codec produces these
+	for _, accept := range []string{"image/png", "application/json;q=0", "image/png, application/json;q=0"} {
+
+		r = httptest.NewRequest("GET", "/people/1", nil)
+		r.Header.Set("Accept", accept)
+		w = httptest.NewRecorder()
+
+		assert.IsType(t, &NotAcceptableError{}, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"}), accept)
+		assert.Equal(t, http.StatusNotAcceptable, w.Code, accept)
+		assert.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"), accept)
+		assert.NotContains(t, w.Body.String(), "Mat", accept)
+
+	}
+
+}
+
+func TestRespond_InvalidCallback(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1?callback=alert(1)//", nil)
+	w := httptest.NewRecorder()
+
+	assert.IsType(t, &jsonp.InvalidCallbackError{}, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"status":400`)
+	assert.NotContains(t, w.Body.String(), "Mat")
+
+}
+
+func TestRespond_Head(t *testing.T) {
+
+	r := httptest.NewRequest("HEAD", "/people/1", nil)
+	w := httptest.NewRecorder()
+
+	if assert.NoError(t, Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})) {
+		assert.Equal(t, "14", w.Header().Get("Content-Length"))
+		assert.Equal(t, 0, w.Body.Len())
+	}
+
+}
+
+func TestRespondError(t *testing.T) {
+
+	var r *http.Request
+	var w *httptest.ResponseRecorder
+
+	// the codec decides the problem type, whichever of its types was accepted
+	for accept, contentType := range map[string]string{
+		"text/xml":         "application/problem+xml; charset=utf-8",
+		"application/xml":  "application/problem+xml; charset=utf-8",
+		"application/json": "application/problem+json; charset=utf-8",
+		"text/json":        "application/problem+json; charset=utf-8",
+	} {
+
+		r = httptest.NewRequest("GET", "/people/1", nil)
+		r.Header.Set("Accept", accept)
+		w = httptest.NewRecorder()
+
+		if assert.NoError(t, RespondError(w, r, codecs.NewProblemDetails(http.StatusNotFound, "No such person")), accept) {
+			assert.Equal(t, http.StatusNotFound, w.Code, accept)
+			assert.Equal(t, contentType, w.Header().Get("Content-Type"), accept)
+			assert.Equal(t, "Accept, Accept-Encoding", w.Header().Get("Vary"), accept)
+			assert.Equal(t, "", w.Header().Get("Content-Location"), accept)
+			assert.Contains(t, w.Body.String(), "No such person", accept)
+		}
+
+	}
+
+	w = httptest.NewRecorder()
+	assert.NoError(t, RespondError(w, r, nil))
+	assert.Equal(t, 0, w.Body.Len())
+
+}
+
+func TestRespondError_XML(t *testing.T) {
+
+	r := httptest.NewRequest("POST", "/people", strings.NewReader(`<name>`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "text/xml")
+	w := httptest.NewRecorder()
+
+	var object map[string]interface{}
+	decodeErr := Decode(r, &object)
+
+	if assert.Error(t, decodeErr) && assert.NoError(t, RespondError(w, r, decodeErr)) {
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var problem struct {
+			Status string `xml:"status"`
+			Detail string `xml:"detail"`
+		}
+
+		if assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &problem), w.Body.String()) {
+			assert.Equal(t, "400", strings.TrimSpace(problem.Status))
+			assert.Contains(t, problem.Detail, "invalid character '<'")
+		}
+
+	}
+
+}
+
+func TestRespondError_CannotMarshalProblem(t *testing.T) {
+
+	r := httptest.NewRequest("GET", "/people/1", nil)
+	r.Header.Set("Accept", constants.ContentTypeProtobuf)
+	w := httptest.NewRecorder()
+
+	// protobuf can only marshal proto.Message values
+	if assert.NoError(t, RespondError(w, r, codecs.NewProblemDetails(http.StatusNotFound, "No such person"))) {
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"))
+
+		var problem map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem)) {
+			assert.Equal(t, "No such person", problem["detail"])
+		}
+
+	}
+
+}
+
+func TestDecode(t *testing.T) {
+
+	r := httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat","age":30}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var object person
+
+	if assert.NoError(t, Decode(r, &object)) {
+		assert.Equal(t, person{"Mat", 30}, object)
+	}
+
+}
+
+func TestDecode_Compressed(t *testing.T) {
+
+	body := new(bytes.Buffer)
+	writer := gzip.NewWriter(body)
+	writer.Write([]byte(`{"name":"Mat","age":30}`))
+	writer.Close()
+
+	r := httptest.NewRequest("POST", "/people", body)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "gzip")
+
+	var object person
+
+	if assert.NoError(t, Decode(r, &object)) {
+		assert.Equal(t, person{"Mat", 30}, object)
+	}
+
+}
+
+func TestDecode_Errors(t *testing.T) {
+
+	var object person
+
+	r := httptest.NewRequest("POST", "/people", strings.NewReader(`name: Mat`))
+	r.Header.Set("Content-Type", "application/vnd.unknown")
+
+	err := Decode(r, &object)
+
+	if assert.IsType(t, &services.ContentTypeNotSupportedError{}, err) {
+
+		w := httptest.NewRecorder()
+		RespondError(w, r, err)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	}
+
+	r = httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":`))
+	r.Header.Set("Content-Type", "application/json")
+
+	err = Decode(r, &object)
+
+	if assert.IsType(t, &DecodeError{}, err) {
+
+		w := httptest.NewRecorder()
+		RespondError(w, r, err)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	}
+
+}
+
+func TestHelper_CodecService(t *testing.T) {
+
+	// services without compression still respond and decode
+	helper := &Helper{CodecService: uncompressedService{services.NewWebCodecService()}}
+
+	r := httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat","age":30}`))
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	var object person
+
+	if assert.NoError(t, helper.Decode(r, &object)) && assert.NoError(t, helper.Respond(w, r, http.StatusOK, object)) {
+		assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		assert.Equal(t, `{"name":"Mat","age":30}`, w.Body.String())
+	}
+
+}
+
+// uncompressedService is a CodecService that cannot compress.
+type uncompressedService struct {
+	services.CodecService
+}
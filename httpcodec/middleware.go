@@ -0,0 +1,106 @@
+package httpcodec
+
+import (
+	"context"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/services"
+	"net/http"
+)
+
+// contextKey is the type of the keys of the values the Middleware stores in the
+// request context.
+type contextKey int
+
+const (
+	responseKey contextKey = iota
+	requestKey
+)
+
+// negotiation is the result, and the options to marshal with, negotiated for
+// responding to a request.
+type negotiation struct {
+	result  *services.NegotiationResult
+	options map[string]interface{}
+}
+
+// Middleware wraps the handler with the DefaultHelper's Middleware.
+func Middleware(next http.Handler) http.Handler {
+	return DefaultHelper.Middleware(next)
+}
+
+// ResponseCodec gets the codec the Middleware negotiated for responding to the
+// request.
+func ResponseCodec(r *http.Request) (codecs.Codec, bool) {
+	negotiated, ok := r.Context().Value(responseKey).(*negotiation)
+	if !ok {
+		return nil, false
+	}
+	return negotiated.result.Codec, true
+}
+
+// ResponseNegotiation gets the NegotiationResult explaining why the Middleware
+// chose the codec for responding to the request, such as for debug logging.
+func ResponseNegotiation(r *http.Request) (*services.NegotiationResult, bool) {
+	negotiated, ok := r.Context().Value(responseKey).(*negotiation)
+	if !ok {
+		return nil, false
+	}
+	return negotiated.result, true
+}
+
+// RequestCodec gets the codec the Middleware chose for unmarshalling the
+// request body.  There is no codec for requests without a body.
+func RequestCodec(r *http.Request) (codecs.Codec, bool) {
+	codec, ok := r.Context().Value(requestKey).(codecs.Codec)
+	return codec, ok
+}
+
+// Middleware wraps the handler, negotiating the codecs for the request once
+// before the handler is called.  The handler can get them with ResponseCodec and
+// RequestCodec, and Respond and Decode use them.
+//
+// Like Respond, the Middleware writes a 406 Not Acceptable problem, without
+// calling the handler, if no codec can produce a content type in the Accept
+// header.  It writes a 415 Unsupported Media Type problem if a request body has
+// an unsupported Content-Type, and a 413 Request Entity Too Large problem if the
+// request body is larger than the MaxBodySize.
+func (h *Helper) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		result, options, err := h.negotiate(r)
+		if err != nil {
+			h.respondNegotiationError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), responseKey, &negotiation{result, options})
+
+		if hasBody(r) {
+
+			if h.MaxBodySize > 0 {
+				if r.ContentLength > h.MaxBodySize {
+					h.RespondError(w, r.WithContext(ctx), &RequestTooLargeError{h.MaxBodySize})
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, h.MaxBodySize)
+			}
+
+			requestCodec, err := h.requestCodec(r)
+			if err != nil {
+				h.RespondError(w, r.WithContext(ctx), err)
+				return
+			}
+
+			ctx = context.WithValue(ctx, requestKey, requestCodec)
+
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+	})
+}
+
+// hasBody gets whether the request has a body.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
@@ -0,0 +1,270 @@
+package httpcodec
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/compression"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/services"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echoHandler decodes the request body and responds with it.
+func echoHandler(helper *Helper) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var object map[string]interface{}
+		if err := helper.Decode(r, &object); err != nil {
+			helper.RespondError(w, r, err)
+			return
+		}
+
+		helper.Respond(w, r, http.StatusOK, object)
+
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+
+	var responseCodec, requestCodec codecs.Codec
+	var result *services.NegotiationResult
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseCodec, _ = ResponseCodec(r)
+		result, _ = ResponseNegotiation(r)
+		requestCodec, _ = RequestCodec(r)
+		Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})
+	}))
+
+	r := httptest.NewRequest("POST", "/people", strings.NewReader("name=Mat"))
+	r.Header.Set("Accept", "text/csv, application/json;q=0.5")
+	r.Header.Set("Content-Type", constants.ContentTypeForm)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept, Accept-Encoding", w.Header().Get("Vary"))
+	assert.Equal(t, "/people.csv", w.Header().Get("Content-Location"))
+
+	if assert.NotNil(t, responseCodec) && assert.NotNil(t, requestCodec) {
+		assert.Equal(t, constants.ContentTypeCSV, responseCodec.ContentType())
+		assert.Equal(t, constants.ContentTypeForm, requestCodec.ContentType())
+	}
+
+	if assert.NotNil(t, result) {
+		assert.Equal(t, services.SourceAccept, result.Source)
+		assert.Equal(t, float32(1), result.Quality)
+	}
+
+	// no codec is stored outside the middleware
+	_, ok := ResponseCodec(r)
+	assert.False(t, ok)
+	_, ok = RequestCodec(r)
+	assert.False(t, ok)
+	_, ok = ResponseNegotiation(r)
+	assert.False(t, ok)
+
+}
+
+func TestMiddleware_NoBody(t *testing.T) {
+
+	called := false
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, called = ResponseCodec(r)
+		_, ok := RequestCodec(r)
+		assert.False(t, ok)
+	}))
+
+	r := httptest.NewRequest("GET", "/people", nil)
+	r.Header.Set("Content-Type", "application/vnd.unknown")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.True(t, called)
+
+}
+
+func TestMiddleware_NotAcceptable(t *testing.T) {
+
+	called := false
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	r := httptest.NewRequest("GET", "/people", nil)
+	r.Header.Set("Accept", "image/png, application/json;q=0")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"accept":"image/png, application/json;q=0"`)
+
+	// wildcards, extensions and callbacks are acceptable
+	for _, target := range []string{"/people", "/people.xml", "/people?callback=show"} {
+
+		r = httptest.NewRequest("GET", target, nil)
+		r.Header.Set("Accept", "image/png, */*;q=0.1")
+		if target != "/people" {
+			r.Header.Set("Accept", "image/png")
+		}
+		w = httptest.NewRecorder()
+
+		handler.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code, target)
+
+	}
+
+}
+
+func TestMiddleware_MediaRange(t *testing.T) {
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Respond(w, r, http.StatusOK, map[string]interface{}{"name": "Mat"})
+	}))
+
+	r := httptest.NewRequest("GET", "/people", nil)
+	r.Header.Set("Accept", "text/*")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+
+	r = httptest.NewRequest("GET", "/people", nil)
+	r.Header.Set("Accept", "image/*")
+	w = httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+
+}
+
+func TestMiddleware_UnsupportedMediaType(t *testing.T) {
+
+	called := false
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	r := httptest.NewRequest("POST", "/people", strings.NewReader("name: Mat"))
+	r.Header.Set("Content-Type", "application/vnd.unknown")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.False(t, called)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Contains(t, w.Body.String(), `"contentType":"application/vnd.unknown"`)
+
+}
+
+func TestMiddleware_MaxBodySize(t *testing.T) {
+
+	helper := &Helper{CodecService: services.NewWebCodecService(), MaxBodySize: 10}
+	handler := helper.Middleware(echoHandler(helper))
+
+	r := httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat Ryer"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), `"limit":10`)
+
+	// without a known length, the limit is enforced while reading
+	r = httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat Ryer"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.ContentLength = -1
+	w = httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	r = httptest.NewRequest("POST", "/people", strings.NewReader(`{"a":"b"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"a":"b"}`, w.Body.String())
+
+}
+
+func TestDecode_MaxBodySize(t *testing.T) {
+
+	helper := &Helper{CodecService: services.NewWebCodecService(), MaxBodySize: 10}
+	var object map[string]interface{}
+
+	r := httptest.NewRequest("POST", "/people", strings.NewReader(`{"name":"Mat Ryer"}`))
+	r.ContentLength = -1
+
+	err := helper.Decode(r, &object)
+
+	if assert.IsType(t, &RequestTooLargeError{}, err) {
+		assert.Equal(t, int64(10), err.(*RequestTooLargeError).Limit)
+	}
+
+}
+
+func TestMaxBodySize_Decompressed(t *testing.T) {
+
+	helper := &Helper{CodecService: services.NewWebCodecService(), MaxBodySize: 100}
+
+	// a small body that decompresses to more than the limit
+	data, err := compression.NewCompressingCodec(new(json.JsonCodec), new(compression.GzipCoding)).Marshal(map[string]interface{}{"name": strings.Repeat("a", 1000)}, nil)
+
+	if assert.NoError(t, err) {
+
+		assert.True(t, len(data) < 100)
+
+		r := httptest.NewRequest("POST", "/people", bytes.NewReader(data))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Content-Encoding", "gzip")
+
+		var object map[string]interface{}
+		err := helper.Decode(r, &object)
+
+		if assert.IsType(t, &RequestTooLargeError{}, err) {
+			assert.Equal(t, int64(100), err.(*RequestTooLargeError).Limit)
+		}
+
+		r = httptest.NewRequest("POST", "/people", bytes.NewReader(data))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		helper.Middleware(echoHandler(helper)).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), `"limit":100`)
+
+		// the service's own limit still applies when it is smaller
+		helper.CodecService.(*services.WebCodecService).MaxDecompressedSize = 50
+		r = httptest.NewRequest("POST", "/people", bytes.NewReader(data))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Content-Encoding", "gzip")
+
+		err = helper.Decode(r, &object)
+
+		if assert.IsType(t, &RequestTooLargeError{}, err) {
+			assert.Equal(t, int64(50), err.(*RequestTooLargeError).Limit)
+		}
+
+	}
+
+}
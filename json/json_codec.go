@@ -8,6 +8,7 @@ import (
 var validJsonContentTypes = []string{
 	"application/json",
 	"text/json",
+	constants.ContentTypeProblemJSON,
 }
 
 // JsonCodec converts objects to and from JSON.
@@ -62,3 +62,27 @@ func TestCanMarshalWithCallback(t *testing.T) {
 	assert.False(t, codec.CanMarshalWithCallback())
 
 }
+
+func TestContentTypeSupported(t *testing.T) {
+
+	assert.True(t, codec.ContentTypeSupported(constants.ContentTypeJSON))
+	assert.True(t, codec.ContentTypeSupported("text/json"))
+	assert.True(t, codec.ContentTypeSupported(constants.ContentTypeProblemJSON))
+	assert.False(t, codec.ContentTypeSupported(constants.ContentTypeXML))
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(JsonCodec)
+
+	f.Add([]byte(`{"name":"Mat","age":30,"tags":["a","b"]}`))
+	f.Add([]byte(`[{"a":null},{"b":1e400}]`))
+	f.Add([]byte(`{"unterminated":`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
@@ -0,0 +1,92 @@
+package jsonp
+
+// MaxCallbackLength is the maximum length of a callback name that will be
+// accepted.  Long callbacks are rejected to limit the amount of client controlled
+// content at the start of the response.
+var MaxCallbackLength int = 128
+
+// reservedWords are the JavaScript reserved words that cannot be used as the
+// first identifier of a callback.
+var reservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true, "do": true,
+	"else": true, "enum": true, "export": true, "extends": true, "false": true,
+	"finally": true, "for": true, "function": true, "if": true, "implements": true,
+	"import": true, "in": true, "instanceof": true, "interface": true, "let": true,
+	"new": true, "null": true, "package": true, "private": true, "protected": true,
+	"public": true, "return": true, "static": true, "super": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "typeof": true,
+	"var": true, "void": true, "while": true, "with": true, "yield": true,
+}
+
+// IsValidCallback gets whether the specified callback is safe to use as the
+// function name in a JSONP response.
+//
+// Valid callbacks are JavaScript identifiers, optionally followed by any number
+// of member accessors, either dotted identifiers or numeric indexes.  For example:
+//
+//	callback
+//	jQuery1234_5678
+//	$.callbacks.done
+//	handlers[0].done
+func IsValidCallback(callback string) bool {
+
+	if len(callback) == 0 || len(callback) > MaxCallbackLength {
+		return false
+	}
+
+	// the first segment must be an identifier that is not reserved
+	end := scanIdentifier(callback, 0)
+	if end == 0 || reservedWords[callback[:end]] {
+		return false
+	}
+
+	for pos := end; pos < len(callback); pos = end {
+
+		switch callback[pos] {
+		case '.':
+			end = scanIdentifier(callback, pos+1)
+			if end == pos+1 {
+				return false
+			}
+		case '[':
+			end = scanDigits(callback, pos+1)
+			if end == pos+1 || end >= len(callback) || callback[end] != ']' {
+				return false
+			}
+			end++
+		default:
+			return false
+		}
+
+	}
+
+	return true
+}
+
+// scanIdentifier returns the position of the end of the identifier starting at
+// start, or start if there is no identifier.
+func scanIdentifier(s string, start int) int {
+	pos := start
+	for ; pos < len(s); pos++ {
+		c := s[pos]
+		if c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			continue
+		}
+		if pos > start && c >= '0' && c <= '9' {
+			continue
+		}
+		break
+	}
+	return pos
+}
+
+// scanDigits returns the position of the end of the digits starting at start,
+// or start if there are no digits.
+func scanDigits(s string, start int) int {
+	pos := start
+	for pos < len(s) && s[pos] >= '0' && s[pos] <= '9' {
+		pos++
+	}
+	return pos
+}
@@ -0,0 +1,63 @@
+package jsonp
+
+import (
+	"github.com/stretchr/codecs"
+	"net/http"
+	"reflect"
+)
+
+// An InvalidCallbackError describes an invalid callback passed in the
+// constants.OptionKeyClientCallback option.  The callback must be a string
+// containing a JavaScript identifier or member path (such as "jQuery1234.callbacks[0]").
+type InvalidCallbackError struct {
+	Callback interface{}
+}
+
+func (e *InvalidCallbackError) Error() string {
+	if callback, ok := e.Callback.(string); ok {
+		return "codecs: jsonp: invalid callback \"" + callback + "\""
+	}
+	return "codecs: jsonp: callback must be a string, not " + typeString(e.Callback)
+}
+
+// ProblemDetails gets the ProblemDetails describing this error as a
+// 400 Bad Request problem, since the callback comes from the request.
+func (e *InvalidCallbackError) ProblemDetails() *codecs.ProblemDetails {
+	return codecs.NewProblemDetails(http.StatusBadRequest, e.Error())
+}
+
+// An InvalidContextError describes an invalid client context passed in the
+// constants.OptionKeyClientContext option.  The context must be a string.
+type InvalidContextError struct {
+	Context interface{}
+}
+
+func (e *InvalidContextError) Error() string {
+	return "codecs: jsonp: context must be a string, not " + typeString(e.Context)
+}
+
+// typeString gets the name of the type of the specified object, for use in
+// error messages.
+func typeString(obj interface{}) string {
+	if obj == nil {
+		return "nil"
+	}
+	return reflect.TypeOf(obj).String()
+}
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: jsonp: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: jsonp: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: jsonp: Unmarshal(nil " + e.Type.String() + ")"
+}
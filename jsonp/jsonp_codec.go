@@ -1,10 +1,12 @@
 package jsonp
 
 import (
+	"bytes"
 	jsonEncoding "encoding/json"
 	"errors"
 	"github.com/stretchr/codecs/constants"
 	stewstrings "github.com/stretchr/stew/strings"
+	"reflect"
 )
 
 var validJsonpContentTypes = []string{
@@ -16,51 +18,190 @@ var validJsonpContentTypes = []string{
 var ErrorMissingCallback = errors.New("A callback is required for JSONP")
 
 // ErrorUnmarshalNotSupported is the error for when Unmarshal is called but not supported.
+//
+// Deprecated: Unmarshal is now supported, so this error is no longer returned.
 var ErrorUnmarshalNotSupported = errors.New("Unmarshalling an object is not supported for JSONP")
 
+// ErrorInvalidPayload is the error for when Unmarshal is called with data that is not
+// a callback wrapping a JSON payload.
+var ErrorInvalidPayload = errors.New("JSONP data must be in the form callback(json) or callback(json,\"context\")")
+
+// Payload holds the parts of unmarshalled JSONP data.
+//
+// If a *Payload is passed to Unmarshal, the callback and context are made available
+// along with the data.  Data is decoded into the existing value if it is a non-nil
+// pointer, otherwise Data is set to the decoded JSON.
+type Payload struct {
+
+	// Callback is the name of the callback function wrapping the data.
+	Callback string
+
+	// Context is the client context passed as the second argument to the callback.
+	Context string
+
+	// HasContext is whether a client context was present.
+	HasContext bool
+
+	// Data is the object decoded from the JSON payload.
+	Data interface{}
+}
+
+// callbackPrefix is written before the callback to defeat content sniffing attacks
+// (such as Rosetta Flash) that rely on controlling the first bytes of the response.
+const callbackPrefix string = "/**/"
+
 // JsonPCodec converts objects to JSONP.
 type JsonPCodec struct{}
 
 // Marshal converts an object to JSONP.
+//
+// The options must contain the name of the callback function in the
+// constants.OptionKeyClientCallback key, and may optionally contain a client context
+// string in the constants.OptionKeyClientContext key, which will be passed as the
+// second argument to the callback.
+//
+// Callbacks are validated with IsValidCallback, and the output is prefixed with an
+// empty comment to prevent the response from being interpreted as anything other
+// than JavaScript.
 func (c *JsonPCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
 
-	if len(options) == 0 {
+	// #codec-context-options
+	// the callback option is required, and the client-context
+	// (NB: not *Context) option is optional.
+
+	callbackValue, hasCallback := options[constants.OptionKeyClientCallback]
+
+	if !hasCallback {
 		return nil, ErrorMissingCallback
 	}
 
+	callbackFunctionName, ok := callbackValue.(string)
+
+	if !ok || !IsValidCallback(callbackFunctionName) {
+		return nil, &InvalidCallbackError{callbackValue}
+	}
+
 	json, err := jsonEncoding.Marshal(object)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// #codec-context-options
-	// the assumption is options[0] is the callback parameter,
-	// and options[1] is the client-context (NB: not *Context) string.
-
-	var callbackFunctionName string
 	var callbackString string
-	var clientContextString string
-	var ok bool
 
-	if callbackFunctionName, ok = options[constants.OptionKeyClientCallback].(string); !ok {
-		panic("stretchrcom/codecs: JSONP requires the options to contain the constants.OptionKeyClientCallback value.")
-	}
+	if contextValue, hasClientContext := options[constants.OptionKeyClientContext]; !hasClientContext {
+		callbackString = stewstrings.MergeStrings(callbackPrefix, callbackFunctionName, "(", string(json), ");")
+	} else {
 
-	clientContextString, hasClientContext := options[constants.OptionKeyClientContext].(string)
+		clientContextString, ok := contextValue.(string)
 
-	if !hasClientContext {
-		callbackString = stewstrings.MergeStrings(callbackFunctionName, "(", string(json), ");")
-	} else {
-		callbackString = stewstrings.MergeStrings(options[constants.OptionKeyClientCallback].(string), "(", string(json), `,"`, clientContextString, `"`, ");")
+		if !ok {
+			return nil, &InvalidContextError{contextValue}
+		}
+
+		// the context is encoded as a JSON string, so it cannot escape the quotes
+		clientContextJson, err := jsonEncoding.Marshal(clientContextString)
+
+		if err != nil {
+			return nil, err
+		}
+
+		callbackString = stewstrings.MergeStrings(callbackPrefix, callbackFunctionName, "(", string(json), ",", string(clientContextJson), ");")
 	}
 
-	return []byte(callbackString), nil
+	return escapeLineTerminators([]byte(callbackString)), nil
 }
 
-// Unmarshal is not supported for JSONP. Returns an error.
+// Unmarshal converts JSONP into an object.
+//
+// The callback wrapper (and the optional client context argument) is stripped and the
+// JSON payload is unmarshalled into obj.  To get the callback and context as well,
+// pass a *Payload.
 func (c *JsonPCodec) Unmarshal(data []byte, obj interface{}) error {
-	return ErrorUnmarshalNotSupported
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	payload, json, err := parsePayload(data)
+
+	if err != nil {
+		return err
+	}
+
+	target, isPayload := obj.(*Payload)
+
+	if !isPayload {
+		return jsonEncoding.Unmarshal(json, obj)
+	}
+
+	target.Callback = payload.Callback
+	target.Context = payload.Context
+	target.HasContext = payload.HasContext
+
+	// decode into the existing data object if there is one
+	if dataValue := reflect.ValueOf(target.Data); dataValue.Kind() == reflect.Ptr && !dataValue.IsNil() {
+		return jsonEncoding.Unmarshal(json, target.Data)
+	}
+
+	return jsonEncoding.Unmarshal(json, &target.Data)
+}
+
+// parsePayload splits JSONP data into its callback, context and raw JSON payload.
+func parsePayload(data []byte) (*Payload, []byte, error) {
+
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte(callbackPrefix))
+	data = bytes.TrimSuffix(data, []byte(";"))
+	data = bytes.TrimSpace(data)
+
+	open := bytes.IndexByte(data, '(')
+	if open == -1 || data[len(data)-1] != ')' {
+		return nil, nil, ErrorInvalidPayload
+	}
+
+	payload := &Payload{Callback: string(bytes.TrimSpace(data[:open]))}
+
+	if !IsValidCallback(payload.Callback) {
+		return nil, nil, &InvalidCallbackError{payload.Callback}
+	}
+
+	arguments := data[open+1 : len(data)-1]
+
+	// read the JSON payload
+	var json jsonEncoding.RawMessage
+	decoder := jsonEncoding.NewDecoder(bytes.NewReader(arguments))
+	if err := decoder.Decode(&json); err != nil {
+		return nil, nil, ErrorInvalidPayload
+	}
+
+	// read the optional context
+	remaining := bytes.TrimSpace(arguments[decoder.InputOffset():])
+
+	if len(remaining) > 0 {
+
+		if remaining[0] != ',' {
+			return nil, nil, ErrorInvalidPayload
+		}
+
+		if err := jsonEncoding.Unmarshal(remaining[1:], &payload.Context); err != nil {
+			return nil, nil, ErrorInvalidPayload
+		}
+
+		payload.HasContext = true
+
+	}
+
+	return payload, json, nil
+}
+
+// escapeLineTerminators escapes the U+2028 LINE SEPARATOR and U+2029 PARAGRAPH SEPARATOR
+// characters, which are valid in JSON strings but terminate lines in JavaScript.
+func escapeLineTerminators(data []byte) []byte {
+	data = bytes.Replace(data, []byte("\u2028"), []byte(`\u2028`), -1)
+	return bytes.Replace(data, []byte("\u2029"), []byte(`\u2029`), -1)
 }
 
 // ContentType returns the content type for this codec.
@@ -4,6 +4,8 @@ import (
 	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -47,7 +49,7 @@ func TestMarshal(t *testing.T) {
 		t.Errorf("Shouldn't return error: %s", jsonPError)
 	}
 
-	assert.Equal(t, string(jsonPString), `candyCorn({"name":"Mat"});`)
+	assert.Equal(t, string(jsonPString), `/**/candyCorn({"name":"Mat"});`)
 
 }
 
@@ -64,7 +66,7 @@ func TestMarshal_WithContext(t *testing.T) {
 		t.Errorf("Shouldn't return error: %s", jsonPError)
 	}
 
-	assert.Equal(t, string(jsonPString), `candyCorn({"name":"Mat"},"halloween");`)
+	assert.Equal(t, string(jsonPString), `/**/candyCorn({"name":"Mat"},"halloween");`)
 
 }
 
@@ -80,14 +82,186 @@ func TestMarshal_WithoutCallback(t *testing.T) {
 	assert.Equal(t, jsonPError, ErrorMissingCallback)
 }
 
+func TestMarshal_WithoutCallbackOption(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	_, jsonPError := codec.Marshal(map[string]string{"name": "Mat"}, map[string]interface{}{"not-relevant": true})
+
+	assert.Equal(t, jsonPError, ErrorMissingCallback)
+}
+
+func TestMarshal_WithInvalidCallback(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	for _, callback := range []interface{}{"alert(1);x", "a b", "</script>", 123, nil} {
+
+		_, jsonPError := codec.Marshal(map[string]string{"name": "Mat"}, map[string]interface{}{constants.OptionKeyClientCallback: callback})
+
+		if assert.IsType(t, &InvalidCallbackError{}, jsonPError, "%v", callback) {
+			assert.Equal(t, callback, jsonPError.(*InvalidCallbackError).Callback)
+		}
+
+	}
+
+}
+
+func TestMarshal_WithInvalidContext(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	_, jsonPError := codec.Marshal(map[string]string{"name": "Mat"}, map[string]interface{}{constants.OptionKeyClientCallback: "candyCorn", constants.OptionKeyClientContext: 123})
+
+	if assert.IsType(t, &InvalidContextError{}, jsonPError) {
+		assert.Equal(t, "codecs: jsonp: context must be a string, not int", jsonPError.Error())
+	}
+
+}
+
+func TestMarshal_EscapesContext(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	jsonPString, jsonPError := codec.Marshal(map[string]string{"name": "Mat"}, map[string]interface{}{constants.OptionKeyClientCallback: "candyCorn", constants.OptionKeyClientContext: `");alert(1);//`})
+
+	if assert.NoError(t, jsonPError) {
+		assert.Equal(t, `/**/candyCorn({"name":"Mat"},"\");alert(1);//");`, string(jsonPString))
+	}
+
+}
+
+func TestMarshal_EscapesLineTerminators(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	jsonPString, jsonPError := codec.Marshal(map[string]string{"name": "Mat\u2028Ryer"}, map[string]interface{}{constants.OptionKeyClientCallback: "candyCorn", constants.OptionKeyClientContext: "a\u2029b"})
+
+	if assert.NoError(t, jsonPError) {
+		assert.Equal(t, `/**/candyCorn({"name":"Mat\u2028Ryer"},"a\u2029b");`, string(jsonPString))
+	}
+
+}
+
+func TestEscapeLineTerminators(t *testing.T) {
+
+	assert.Equal(t, `a\u2028b\u2029c`, string(escapeLineTerminators([]byte("a\u2028b\u2029c"))))
+
+}
+
+func TestIsValidCallback(t *testing.T) {
+
+	valid := []string{"callback", "jQuery1234_5678", "$", "_cb", "$.callbacks.done", "handlers[0].done", "a.default", "a[10][2]"}
+	invalid := []string{"", "1callback", "alert(1)", "a b", "a.", "a..b", "a[", "a[]", "a[x]", "a[0", "a-b", "a;b", "default", "this.x", "<script>", "callback\u2028"}
+
+	for _, callback := range valid {
+		assert.True(t, IsValidCallback(callback), callback)
+	}
+	for _, callback := range invalid {
+		assert.False(t, IsValidCallback(callback), callback)
+	}
+
+	assert.False(t, IsValidCallback(strings.Repeat("a", MaxCallbackLength+1)), "Long callbacks should be rejected")
+
+}
+
 func TestUnmarshal(t *testing.T) {
 
 	codec := new(JsonPCodec)
 
-	jsonString := `{"name":"Mat"}`
+	jsonPString := `/**/candyCorn({"name":"Mat"});`
+	var object map[string]interface{}
+
+	jsonPError := codec.Unmarshal([]byte(jsonPString), &object)
+
+	if assert.NoError(t, jsonPError) {
+		assert.Equal(t, "Mat", object["name"])
+	}
+}
+
+func TestUnmarshal_RoundTrip(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	options := map[string]interface{}{constants.OptionKeyClientCallback: "handlers[0].done", constants.OptionKeyClientContext: `hallo"ween`}
+	jsonPString, jsonPError := codec.Marshal(map[string]interface{}{"name": "Mat"}, options)
+
+	if assert.NoError(t, jsonPError) {
+
+		var payload Payload
+
+		if assert.NoError(t, codec.Unmarshal(jsonPString, &payload)) {
+			assert.Equal(t, "handlers[0].done", payload.Callback)
+			assert.Equal(t, `hallo"ween`, payload.Context)
+			assert.True(t, payload.HasContext)
+			assert.Equal(t, map[string]interface{}{"name": "Mat"}, payload.Data)
+		}
+
+	}
+
+}
+
+func TestUnmarshal_PayloadWithData(t *testing.T) {
+
+	codec := new(JsonPCodec)
+
+	var object struct {
+		Name string `json:"name"`
+	}
+	payload := Payload{Data: &object}
+
+	if assert.NoError(t, codec.Unmarshal([]byte(`candyCorn( {"name":"Mat"} )`), &payload)) {
+		assert.Equal(t, "candyCorn", payload.Callback)
+		assert.False(t, payload.HasContext)
+		assert.Equal(t, "Mat", object.Name, "Data should be decoded into the existing object")
+	}
+
+}
+
+func TestUnmarshal_InvalidPayload(t *testing.T) {
+
+	codec := new(JsonPCodec)
+	var object interface{}
+
+	for _, jsonPString := range []string{``, `{"name":"Mat"}`, `candyCorn({"name":"Mat"}`, `candyCorn()`, `candyCorn({"name":"Mat"},halloween)`, `candyCorn({"name":"Mat"} "halloween")`, `candyCorn({"name":"Mat"},"a","b")`} {
+		assert.Equal(t, ErrorInvalidPayload, codec.Unmarshal([]byte(jsonPString), &object), jsonPString)
+	}
+
+	assert.IsType(t, &InvalidCallbackError{}, codec.Unmarshal([]byte(`window["alert"]({})`), &object))
+
+}
+
+func TestUnmarshal_NonPointer(t *testing.T) {
+
+	codec := new(JsonPCodec)
 	var object map[string]interface{}
 
-	jsonPError := codec.Unmarshal([]byte(jsonString), &object)
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal([]byte(`candyCorn({})`), object))
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal([]byte(`candyCorn({})`), nil))
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(JsonPCodec)
+
+	f.Add([]byte(`callback({"name":"Mat"});`))
+	f.Add([]byte(`ns.callback({"name":"Mat"}, "context")`))
+	f.Add([]byte(`callback(`))
+	f.Add([]byte(`{"name":"Mat"}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
+
+func TestInvalidCallbackError_ProblemDetails(t *testing.T) {
+
+	problem := codecs.ProblemDetailsFromError(&InvalidCallbackError{"alert(1)//"})
+
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, `codecs: jsonp: invalid callback "alert(1)//"`, problem.Detail)
 
-	assert.Equal(t, jsonPError, ErrorUnmarshalNotSupported)
 }
@@ -4,28 +4,139 @@ import (
 	"bytes"
 	"github.com/stretchr/codecs/constants"
 	"github.com/ugorji/go/codec"
+	"reflect"
+	"sync"
 )
 
+// HandleOptions configures the msgpack handle used by a MsgpackCodec.
+type HandleOptions struct {
+
+	// RawToString decodes msgpack raw and str values as strings rather than []byte
+	// when decoding into an interface{}.
+	RawToString bool
+
+	// WriteExt encodes using the new msgpack spec, which includes the str and bin types,
+	// extensions and the timestamp extension (type -1) for time.Time values.  Set this
+	// to false to produce data that can be read by decoders that only support the old spec.
+	WriteExt bool
+
+	// MapType is the type of map to make when decoding a map into an interface{}.
+	MapType reflect.Type
+
+	// Canonical sorts map keys when encoding, so the same value always produces the
+	// same bytes.
+	Canonical bool
+
+	// Extensions are additional msgpack extension types to register on the handle.
+	Extensions []Extension
+}
+
+// Extension describes a msgpack extension type.
+type Extension struct {
+
+	// Type is the Go type that is encoded as this extension.
+	Type reflect.Type
+
+	// Tag is the msgpack extension type number.
+	Tag uint64
+
+	// Ext converts values of Type to and from the extension data.
+	Ext codec.BytesExt
+}
+
+// DefaultHandleOptions gets the HandleOptions used by a MsgpackCodec that was not made
+// with NewMsgpackCodec.
+//
+// Strings are decoded as strings, maps are decoded as map[string]interface{} and time.Time
+// values are encoded with the msgpack timestamp extension, so decoded objects are the same
+// shape as those produced by the other codecs.
+func DefaultHandleOptions() HandleOptions {
+	return HandleOptions{
+		RawToString: true,
+		WriteExt:    true,
+		MapType:     reflect.TypeOf(map[string]interface{}(nil)),
+	}
+}
+
 // MsgpackCodec converts objects to and from Msgpack.
-type MsgpackCodec struct{}
+//
+// The zero value uses DefaultHandleOptions.  Use NewMsgpackCodec to configure
+// the handle.
+type MsgpackCodec struct {
+	handle     *codec.MsgpackHandle
+	handleOnce sync.Once
+}
+
+// NewMsgpackCodec makes a new MsgpackCodec that uses a handle configured with
+// the specified options.
+func NewMsgpackCodec(options HandleOptions) (*MsgpackCodec, error) {
+
+	handle, err := newHandle(options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(MsgpackCodec)
+	c.handleOnce.Do(func() {
+		c.handle = handle
+	})
+
+	return c, nil
+}
 
-var msgpackHandle codec.MsgpackHandle
+// newHandle makes a new codec.MsgpackHandle configured with the specified options.
+func newHandle(options HandleOptions) (*codec.MsgpackHandle, error) {
+
+	handle := new(codec.MsgpackHandle)
+	handle.RawToString = options.RawToString
+	handle.WriteExt = options.WriteExt
+	handle.MapType = options.MapType
+	handle.Canonical = options.Canonical
+
+	for _, extension := range options.Extensions {
+		if err := handle.SetBytesExt(extension.Type, extension.Tag, extension.Ext); err != nil {
+			return nil, err
+		}
+	}
+
+	return handle, nil
+}
+
+// Handle gets the codec.MsgpackHandle used by this codec.
+//
+// The handle must not be modified once the codec is in use.
+func (c *MsgpackCodec) Handle() *codec.MsgpackHandle {
+	c.handleOnce.Do(func() {
+		// the default options have no extensions, so cannot fail
+		c.handle, _ = newHandle(DefaultHandleOptions())
+	})
+	return c.handle
+}
 
 // Converts an object to Msgpack.
 func (c *MsgpackCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
 
 	byteBuffer := new(bytes.Buffer)
-	enc := codec.NewEncoder(byteBuffer, &msgpackHandle)
+	enc := codec.NewEncoder(byteBuffer, c.Handle())
 	encErr := enc.Encode(object)
 
 	return byteBuffer.Bytes(), encErr
 }
 
 // Unmarshal converts Msgpack into an object.
+//
+// The obj must be a non-nil pointer, into which the data is decoded directly.
 func (c *MsgpackCodec) Unmarshal(data []byte, obj interface{}) error {
 
-	dec := codec.NewDecoder(bytes.NewReader(data), &msgpackHandle)
-	return dec.Decode(&obj)
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	dec := codec.NewDecoder(bytes.NewReader(data), c.Handle())
+	return dec.Decode(obj)
 }
 
 // ContentType returns the content type for this codec.
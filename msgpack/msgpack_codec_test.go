@@ -4,7 +4,10 @@ import (
 	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/testify/assert"
+	"math"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestInterface(t *testing.T) {
@@ -44,7 +47,242 @@ func TestUnmarshal(t *testing.T) {
 		t.Errorf("Shouldn't return error: %s", err)
 	}
 
-	assert.Equal(t, []byte{0x4d, 0x61, 0x74}, object["name"])
+	assert.Equal(t, "Mat", object["name"])
+
+}
+
+func TestUnmarshal_NestedMaps(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+
+	packed, err := codec.Marshal(map[string]interface{}{"address": map[string]interface{}{"city": "Boulder"}}, nil)
+
+	if assert.NoError(t, err) {
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(packed, &object)) {
+			assert.Equal(t, map[string]interface{}{"address": map[string]interface{}{"city": "Boulder"}}, object)
+		}
+
+	}
+
+}
+
+func TestMarshal_Time(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+	when := time.Unix(1363780800, 0).UTC()
+
+	packed, err := codec.Marshal(when, nil)
+
+	if assert.NoError(t, err) {
+
+		// timestamp 32 (fixext 4 with type -1)
+		assert.Equal(t, []byte{0xd6, 0xff, 0x51, 0x49, 0xa4, 0xc0}, packed)
+
+		var object interface{}
+
+		if assert.NoError(t, codec.Unmarshal(packed, &object)) {
+			assert.Equal(t, when, object)
+		}
+
+	}
+
+}
+
+func TestNewMsgpackCodec_OldSpec(t *testing.T) {
+
+	codec, err := NewMsgpackCodec(HandleOptions{})
+
+	if assert.NoError(t, err) {
+
+		packed := []byte{0x81, 0xa4, 0x6e, 0x61, 0x6d, 0x65, 0xa3, 0x4d, 0x61, 0x74}
+		var object map[string]interface{}
+
+		if assert.NoError(t, codec.Unmarshal(packed, &object)) {
+			assert.Equal(t, []byte{0x4d, 0x61, 0x74}, object["name"])
+		}
+
+	}
+
+}
+
+func TestNewMsgpackCodec_Canonical(t *testing.T) {
+
+	options := DefaultHandleOptions()
+	options.Canonical = true
+	codec, err := NewMsgpackCodec(options)
+
+	if assert.NoError(t, err) {
+
+		obj := map[string]interface{}{"c": 3, "a": 1, "b": 2}
+		expectedResult := []byte{0x83, 0xa1, 0x61, 0x01, 0xa1, 0x62, 0x02, 0xa1, 0x63, 0x03}
+
+		for i := 0; i < 10; i++ {
+			packed, err := codec.Marshal(obj, nil)
+			if assert.NoError(t, err) {
+				assert.Equal(t, expectedResult, packed)
+			}
+		}
+
+	}
+
+}
+
+type testPoint struct {
+	X, Y uint8
+}
+
+type testPointExt struct{}
+
+func (e testPointExt) WriteExt(v interface{}) []byte {
+	point := v.(*testPoint)
+	return []byte{point.X, point.Y}
+}
+
+func (e testPointExt) ReadExt(dst interface{}, src []byte) {
+	point := dst.(*testPoint)
+	point.X, point.Y = src[0], src[1]
+}
+
+func TestNewMsgpackCodec_Extensions(t *testing.T) {
+
+	options := DefaultHandleOptions()
+	options.Extensions = []Extension{{Type: reflect.TypeOf(testPoint{}), Tag: 5, Ext: testPointExt{}}}
+	codec, err := NewMsgpackCodec(options)
+
+	if assert.NoError(t, err) {
+
+		packed, err := codec.Marshal(testPoint{1, 2}, nil)
+
+		if assert.NoError(t, err) {
+
+			// fixext 2 with type 5
+			assert.Equal(t, []byte{0xd5, 0x05, 0x01, 0x02}, packed)
+
+			var point testPoint
+
+			if assert.NoError(t, codec.Unmarshal(packed, &point)) {
+				assert.Equal(t, testPoint{1, 2}, point)
+			}
+
+		}
+
+	}
+
+}
+
+func TestUnmarshal_NonPointer(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+	packed := []byte{0x81, 0xa4, 0x6e, 0x61, 0x6d, 0x65, 0xa3, 0x4d, 0x61, 0x74}
+
+	var object map[string]interface{}
+	var nilPointer *map[string]interface{}
+
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal(packed, object))
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal(packed, nil))
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal(packed, nilPointer))
+	assert.Equal(t, "codecs: msgpack: Unmarshal(non-pointer map[string]interface {})", codec.Unmarshal(packed, object).Error())
+
+}
+
+type testPerson struct {
+	Name    string
+	Age     int
+	Address testAddress
+	Tags    []string
+}
+
+type testAddress struct {
+	City     string
+	Postcode uint32
+}
+
+type testNumbers struct {
+	I   int
+	I8  int8
+	I16 int16
+	I32 int32
+	I64 int64
+	U   uint
+	U8  uint8
+	U16 uint16
+	U32 uint32
+	U64 uint64
+	F32 float32
+	F64 float64
+}
+
+func TestUnmarshal_RoundTrip(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		target interface{}
+	}{
+		{"struct", testPerson{"Mat", 30, testAddress{"Boulder", 80301}, []string{"a", "b"}}, new(testPerson)},
+		{"struct pointer", &testPerson{Name: "Tyler"}, new(*testPerson)},
+		{"numbers min", testNumbers{math.MinInt64, math.MinInt8, math.MinInt16, math.MinInt32, math.MinInt64, 0, 0, 0, 0, 0, -math.MaxFloat32, -math.MaxFloat64}, new(testNumbers)},
+		{"numbers max", testNumbers{math.MaxInt64, math.MaxInt8, math.MaxInt16, math.MaxInt32, math.MaxInt64, math.MaxUint64, math.MaxUint8, math.MaxUint16, math.MaxUint32, math.MaxUint64, math.MaxFloat32, math.MaxFloat64}, new(testNumbers)},
+		{"numbers small", testNumbers{1, -1, 2, -2, 3, 4, 5, 6, 7, 8, 0.5, -0.25}, new(testNumbers)},
+		{"map", map[string]string{"name": "Mat"}, new(map[string]string)},
+		{"nested map", map[string]map[string]int{"a": {"b": 1}, "c": {"d": -2}}, new(map[string]map[string]int)},
+		{"slice", []int64{math.MinInt64, -1, 0, 1, math.MaxInt64}, new([]int64)},
+		{"slice of structs", []testAddress{{"Boulder", 80301}, {"London", 0}}, new([]testAddress)},
+		{"byte slice", []byte{0x00, 0x01, 0xff}, new([]byte)},
+		{"string", "Mat", new(string)},
+		{"bool", true, new(bool)},
+		{"int8", int8(math.MinInt8), new(int8)},
+		{"int16", int16(math.MinInt16), new(int16)},
+		{"int32", int32(math.MinInt32), new(int32)},
+		{"int64", int64(math.MinInt64), new(int64)},
+		{"uint8", uint8(math.MaxUint8), new(uint8)},
+		{"uint16", uint16(math.MaxUint16), new(uint16)},
+		{"uint32", uint32(math.MaxUint32), new(uint32)},
+		{"uint64", uint64(math.MaxUint64), new(uint64)},
+		{"float32", float32(math.SmallestNonzeroFloat32), new(float32)},
+		{"float64", math.SmallestNonzeroFloat64, new(float64)},
+	}
+
+	for _, test := range tests {
+
+		packed, err := codec.Marshal(test.value, nil)
+
+		if assert.NoError(t, err, test.name) && assert.NoError(t, codec.Unmarshal(packed, test.target), test.name) {
+			assert.Equal(t, test.value, reflect.ValueOf(test.target).Elem().Interface(), test.name)
+		}
+
+	}
+
+}
+
+func TestUnmarshal_NestedMapsIntoInterface(t *testing.T) {
+
+	codec := new(MsgpackCodec)
+
+	obj := map[string]interface{}{
+		"name": "Mat",
+		"address": map[string]interface{}{
+			"city":   "Boulder",
+			"coords": []interface{}{int64(40), int64(-105)},
+		},
+	}
+
+	packed, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+
+		var object map[string]interface{}
+
+		if assert.NoError(t, codec.Unmarshal(packed, &object)) {
+			assert.Equal(t, obj, object)
+		}
+
+	}
 
 }
 
@@ -68,3 +306,28 @@ func TestCanMarshalWithCallback(t *testing.T) {
 	assert.False(t, codec.CanMarshalWithCallback())
 
 }
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(MsgpackCodec)
+
+	// seed with marshalled objects, so the fuzzer starts from valid data
+	for _, object := range []interface{}{
+		map[string]interface{}{"name": "Mat", "age": 30, "tags": []interface{}{"a", "b"}},
+		map[string]interface{}{"nested": map[string]interface{}{"ok": true, "ratio": 0.5}},
+	} {
+		data, err := codec.Marshal(object, nil)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0xd6, 0xff, 0x00, 0x00, 0x00, 0x00})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
@@ -0,0 +1,17 @@
+// A codec for handling multipart/form-data encoding and decoding.
+//
+// Decoding produces the fields and file parts of the form.  Unmarshal into a
+// *multipart.Form (from the mime/multipart package) to get both, or into a map,
+// interface{} or struct to decode the fields in the same way as the form codec,
+// with file parts as *multipart.FileHeader values.
+//
+// When unmarshalling into a *multipart.Form, file parts larger than MaxMemory
+// are stored in temporary files, which are removed by calling RemoveAll on the
+// multipart.Form.  File parts unmarshalled into anything else are held in memory,
+// and no temporary files are left behind.
+//
+// Encoding turns a map of fields and files (io.Reader values, File values or
+// *multipart.FileHeader values) into a multipart body.  The boundary separating
+// the parts is new for each body, and is given in the options passed to Marshal
+// (see constants.OptionKeyResponseContentType).
+package multipart
@@ -0,0 +1,59 @@
+package multipart
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// ErrorTooLarge is the error for when the data passed to Unmarshal is larger than
+// the codec's MaxSize.
+var ErrorTooLarge = errors.New("codecs: multipart: data is too large")
+
+// ErrorMissingBoundary is the error for when the boundary separating the parts
+// of the data cannot be found.
+var ErrorMissingBoundary = errors.New("codecs: multipart: missing boundary")
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: multipart: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: multipart: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: multipart: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// An UnsupportedTypeError describes a value passed to Marshal that cannot be
+// encoded as a multipart form.  Only maps with string keys can be marshalled.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Type == nil {
+		return "codecs: multipart: Marshal(nil)"
+	}
+	return "codecs: multipart: Marshal(" + e.Type.String() + "): only maps can be encoded as multipart forms"
+}
+
+// A FileTooLargeError describes a file part that is larger than the codec's
+// MaxFileSize.
+type FileTooLargeError struct {
+	Field    string
+	Filename string
+	Size     int64
+	Limit    int64
+}
+
+func (e *FileTooLargeError) Error() string {
+	return "codecs: multipart: file \"" + e.Filename + "\" in field \"" + e.Field + "\" is " +
+		strconv.FormatInt(e.Size, 10) + " bytes, which is more than the limit of " + strconv.FormatInt(e.Limit, 10) + " bytes"
+}
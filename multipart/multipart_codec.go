@@ -0,0 +1,364 @@
+package multipart
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/form"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxMemory is the number of bytes of file parts that are held in memory
+// when the codec's MaxMemory is not set.
+const DefaultMaxMemory int64 = 32 << 20
+
+// defaultFileContentType is the content type of file parts that do not have one.
+const defaultFileContentType string = "application/octet-stream"
+
+var typeForm = reflect.TypeOf(multipart.Form{})
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// File is a file part to be marshalled.
+type File struct {
+
+	// Filename is the name of the file.
+	Filename string
+
+	// ContentType is the content type of the file.  If empty,
+	// application/octet-stream is used.
+	ContentType string
+
+	// Reader provides the content of the file.
+	Reader io.Reader
+}
+
+// MultipartCodec converts objects to and from multipart/form-data.
+type MultipartCodec struct {
+
+	// Boundary is the boundary separating the parts.  If empty, Marshal uses a
+	// new random boundary for each call, and Unmarshal uses the boundary that
+	// starts the data.
+	Boundary string
+
+	// MaxMemory is the number of bytes of file parts held in memory when
+	// unmarshalling into a *multipart.Form.  The rest are stored in temporary
+	// files.  If zero, DefaultMaxMemory is used.
+	MaxMemory int64
+
+	// MaxSize is the largest data, in bytes, that Unmarshal accepts.  If zero,
+	// there is no limit.
+	MaxSize int64
+
+	// MaxFileSize is the largest file part, in bytes, that Unmarshal accepts.  If
+	// zero, there is no limit.
+	MaxFileSize int64
+}
+
+// WithParameters returns a MultipartCodec that uses the boundary in the
+// parameters of a multipart/form-data content type.
+func (c *MultipartCodec) WithParameters(parameters map[string]string) codecs.Codec {
+
+	boundary, ok := parameters["boundary"]
+	if !ok {
+		return c
+	}
+
+	return &MultipartCodec{
+		Boundary:    boundary,
+		MaxMemory:   c.MaxMemory,
+		MaxSize:     c.MaxSize,
+		MaxFileSize: c.MaxFileSize,
+	}
+}
+
+// Marshal converts a map of fields and files into a multipart body.
+//
+// Values that are io.Reader, File, *File or *multipart.FileHeader values (or
+// arrays of them) become file parts.  All other values become fields, which are
+// encoded in the same way as the form codec.
+//
+// Unless the codec has a Boundary, each body gets a new random boundary, which
+// the receiver needs to read it.  If the options are not nil, the content type
+// including the boundary is put in them, with the
+// constants.OptionKeyResponseContentType key.
+func (c *MultipartCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	objectValue := reflect.ValueOf(object)
+	if objectValue.Kind() != reflect.Map || objectValue.Type().Key().Kind() != reflect.String {
+		return nil, &UnsupportedTypeError{reflect.TypeOf(object)}
+	}
+
+	// separate the files from the fields
+	fields := make(map[string]interface{})
+	files := make(map[string][]interface{})
+
+	for _, mapKey := range objectValue.MapKeys() {
+
+		key := mapKey.String()
+		value := objectValue.MapIndex(mapKey).Interface()
+
+		if isFile(value) {
+			files[key] = []interface{}{value}
+			continue
+		}
+
+		if items, ok := value.([]interface{}); ok {
+			var others []interface{}
+			for _, item := range items {
+				if isFile(item) {
+					files[key] = append(files[key], item)
+				} else {
+					others = append(others, item)
+				}
+			}
+			if len(others) == 0 {
+				continue
+			}
+			value = others
+		}
+
+		fields[key] = value
+
+	}
+
+	values, err := form.MarshalValues(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	byteBuffer := new(bytes.Buffer)
+	writer := multipart.NewWriter(byteBuffer)
+
+	if c.Boundary != "" {
+		if err := writer.SetBoundary(c.Boundary); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range sortedKeys(values) {
+		for _, value := range values[key] {
+			if err := writer.WriteField(key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(files) {
+		for _, file := range files[key] {
+			if err := writeFile(writer, key, file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	if options != nil {
+		options[constants.OptionKeyResponseContentType] = writer.FormDataContentType()
+	}
+
+	return byteBuffer.Bytes(), nil
+}
+
+// Unmarshal converts a multipart body into an object.
+//
+// The obj must be a non-nil pointer to a multipart.Form, interface{}, map or
+// struct.  Only a *multipart.Form can hold file parts stored in temporary files,
+// since it can remove them with RemoveAll, so file parts unmarshalled into
+// anything else are always held in memory.
+func (c *MultipartCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	if c.MaxSize > 0 && int64(len(data)) > c.MaxSize {
+		return ErrorTooLarge
+	}
+
+	boundary := c.Boundary
+	if boundary == "" {
+		boundary = findBoundary(data)
+		if boundary == "" {
+			return ErrorMissingBoundary
+		}
+	}
+
+	toForm := rv.Elem().Type() == typeForm
+
+	maxMemory := c.MaxMemory
+	if maxMemory == 0 {
+		maxMemory = DefaultMaxMemory
+	}
+	if !toForm {
+		// the data is already in memory, so the file parts fit too
+		maxMemory = int64(len(data)) + 1
+	}
+
+	multipartForm, err := multipart.NewReader(bytes.NewReader(data), boundary).ReadForm(maxMemory)
+	if err != nil {
+		return err
+	}
+
+	if !toForm {
+		// nothing else has a handle to remove temporary files with
+		defer multipartForm.RemoveAll()
+	}
+
+	if c.MaxFileSize > 0 {
+		for field, fileHeaders := range multipartForm.File {
+			for _, fileHeader := range fileHeaders {
+				if fileHeader.Size > c.MaxFileSize {
+					multipartForm.RemoveAll()
+					return &FileTooLargeError{field, fileHeader.Filename, fileHeader.Size, c.MaxFileSize}
+				}
+			}
+		}
+	}
+
+	if toForm {
+		rv.Elem().Set(reflect.ValueOf(multipartForm).Elem())
+		return nil
+	}
+
+	fields := make(map[string][]interface{})
+	for key, values := range multipartForm.Value {
+		for _, value := range values {
+			fields[key] = append(fields[key], value)
+		}
+	}
+	for key, fileHeaders := range multipartForm.File {
+		for _, fileHeader := range fileHeaders {
+			fields[key] = append(fields[key], fileHeader)
+		}
+	}
+
+	return form.UnmarshalFields(fields, obj)
+}
+
+// ContentType returns the content type for this codec.
+//
+// The boundary is not included, since it is different for each body.  Marshal
+// gives the content type of each body in its options.
+func (c *MultipartCodec) ContentType() string {
+	return constants.ContentTypeMultipart
+}
+
+// FileExtension returns the file extension for this codec.
+//
+// Multipart forms have no file extension, so this is always "".
+func (c *MultipartCodec) FileExtension() string {
+	return ""
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *MultipartCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// ContentTypeSupported returns true if the passed in content type is
+// multipart/form-data.
+func (c *MultipartCodec) ContentTypeSupported(contentType string) bool {
+	return contentType == constants.ContentTypeMultipart
+}
+
+// isFile gets whether the value is marshalled as a file part.
+func isFile(value interface{}) bool {
+	switch value.(type) {
+	case File, *File, *multipart.FileHeader, io.Reader:
+		return true
+	}
+	return false
+}
+
+// writeFile writes a file part to the writer.
+func writeFile(writer *multipart.Writer, key string, value interface{}) error {
+
+	var file File
+
+	switch typedValue := value.(type) {
+	case File:
+		file = typedValue
+	case *File:
+		file = *typedValue
+	case *multipart.FileHeader:
+
+		reader, err := typedValue.Open()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		file = File{typedValue.Filename, typedValue.Header.Get("Content-Type"), reader}
+
+	case io.Reader:
+
+		file = File{Filename: key, Reader: typedValue}
+		if named, ok := typedValue.(interface {
+			Name() string
+		}); ok {
+			file.Filename = filepath.Base(named.Name())
+		}
+
+	}
+
+	if file.ContentType == "" {
+		file.ContentType = defaultFileContentType
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="`+quoteEscaper.Replace(key)+`"; filename="`+quoteEscaper.Replace(file.Filename)+`"`)
+	header.Set("Content-Type", file.ContentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	if file.Reader != nil {
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findBoundary gets the boundary from the first line of the data, which is the
+// boundary preceded by "--".
+func findBoundary(data []byte) string {
+
+	line, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	line = strings.TrimRight(line, " \t\r\n")
+
+	if !strings.HasPrefix(line, "--") {
+		return ""
+	}
+
+	return line[2:]
+}
+
+// sortedKeys gets the keys of the map in order.
+func sortedKeys(object interface{}) []string {
+
+	mapKeys := reflect.ValueOf(object).MapKeys()
+	keys := make([]string, len(mapKeys))
+	for index, mapKey := range mapKeys {
+		keys[index] = mapKey.String()
+	}
+	sort.Strings(keys)
+
+	return keys
+}
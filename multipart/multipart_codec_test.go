@@ -0,0 +1,337 @@
+package multipart
+
+import (
+	"bytes"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"mime/multipart"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testBoundary string = "testboundary"
+
+type upload struct {
+	Title  string                  `form:"title"`
+	Tags   []string                `form:"tags"`
+	Avatar *multipart.FileHeader   `form:"avatar"`
+	Docs   []*multipart.FileHeader `form:"docs"`
+}
+
+// testBody makes a multipart body with two fields and three files.
+func testBody(t testing.TB) []byte {
+
+	codec := &MultipartCodec{Boundary: testBoundary}
+
+	data, err := codec.Marshal(map[string]interface{}{
+		"title":  "Holiday",
+		"tags":   []interface{}{"beach", "sun"},
+		"avatar": File{Filename: "me.png", ContentType: "image/png", Reader: strings.NewReader("PNG")},
+		"docs":   []interface{}{strings.NewReader("one"), &File{Filename: "two.txt", Reader: strings.NewReader("two")}},
+	}, nil)
+
+	assert.NoError(t, err)
+
+	return data
+}
+
+// readFile reads the content of a file part.
+func readFile(t *testing.T, fileHeader *multipart.FileHeader) string {
+
+	file, err := fileHeader.Open()
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer file.Close()
+
+	content, _ := io.ReadAll(file)
+	return string(content)
+}
+
+// tempFiles gets the names of the files in the directory.
+func tempFiles(t *testing.T, dir string) []string {
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names
+}
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(MultipartCodec), "MultipartCodec")
+	assert.Implements(t, (*codecs.ContentTypeMatcherCodec)(nil), new(MultipartCodec), "MultipartCodec")
+	assert.Implements(t, (*codecs.ParameterizedCodec)(nil), new(MultipartCodec), "MultipartCodec")
+
+}
+
+func TestMarshal(t *testing.T) {
+
+	data := testBody(t)
+
+	assert.Equal(t, strings.Join([]string{
+		"--testboundary",
+		`Content-Disposition: form-data; name="tags"`,
+		"",
+		"beach",
+		"--testboundary",
+		`Content-Disposition: form-data; name="tags"`,
+		"",
+		"sun",
+		"--testboundary",
+		`Content-Disposition: form-data; name="title"`,
+		"",
+		"Holiday",
+		"--testboundary",
+		`Content-Disposition: form-data; name="avatar"; filename="me.png"`,
+		"Content-Type: image/png",
+		"",
+		"PNG",
+		"--testboundary",
+		`Content-Disposition: form-data; name="docs"; filename="docs"`,
+		"Content-Type: application/octet-stream",
+		"",
+		"one",
+		"--testboundary",
+		`Content-Disposition: form-data; name="docs"; filename="two.txt"`,
+		"Content-Type: application/octet-stream",
+		"",
+		"two",
+		"--testboundary--",
+		"",
+	}, "\r\n"), string(data))
+
+}
+
+func TestMarshal_GeneratedBoundary(t *testing.T) {
+
+	codec := new(MultipartCodec)
+
+	var boundaries []string
+	for i := 0; i < 2; i++ {
+
+		options := make(map[string]interface{})
+		data, err := codec.Marshal(map[string]interface{}{"title": "Holiday"}, options)
+
+		if assert.NoError(t, err) {
+
+			contentType, _ := options[constants.OptionKeyResponseContentType].(string)
+			assert.True(t, strings.HasPrefix(contentType, "multipart/form-data; boundary="))
+
+			boundary := strings.TrimPrefix(contentType, "multipart/form-data; boundary=")
+			assert.NotEmpty(t, boundary)
+			assert.True(t, bytes.HasPrefix(data, []byte("--"+boundary+"\r\n")))
+
+			boundaries = append(boundaries, boundary)
+
+		}
+
+	}
+
+	// each body gets its own boundary, which is not published by the codec
+	if assert.Equal(t, 2, len(boundaries)) {
+		assert.NotEqual(t, boundaries[0], boundaries[1])
+	}
+	assert.Equal(t, constants.ContentTypeMultipart, codec.ContentType())
+
+	// without options, the boundary can still be read from the data
+	data, err := codec.Marshal(map[string]interface{}{"title": "Holiday"}, nil)
+	if assert.NoError(t, err) {
+		var object map[string]interface{}
+		assert.NoError(t, codec.Unmarshal(data, &object))
+		assert.Equal(t, "Holiday", object["title"])
+	}
+
+	_, err = codec.Marshal([]interface{}{"a"}, nil)
+	assert.IsType(t, &UnsupportedTypeError{}, err)
+
+}
+
+func TestUnmarshal_Form(t *testing.T) {
+
+	codec := new(MultipartCodec)
+	var object multipart.Form
+
+	if assert.NoError(t, codec.Unmarshal(testBody(t), &object)) {
+
+		defer object.RemoveAll()
+
+		assert.Equal(t, []string{"Holiday"}, object.Value["title"])
+		assert.Equal(t, []string{"beach", "sun"}, object.Value["tags"])
+
+		if assert.Equal(t, 1, len(object.File["avatar"])) {
+			assert.Equal(t, "me.png", object.File["avatar"][0].Filename)
+			assert.Equal(t, "image/png", object.File["avatar"][0].Header.Get("Content-Type"))
+			assert.Equal(t, "PNG", readFile(t, object.File["avatar"][0]))
+		}
+
+	}
+
+}
+
+func TestUnmarshal_Map(t *testing.T) {
+
+	codec := new(MultipartCodec)
+	var object map[string]interface{}
+
+	if assert.NoError(t, codec.Unmarshal(testBody(t), &object)) {
+
+		assert.Equal(t, "Holiday", object["title"])
+		assert.Equal(t, []interface{}{"beach", "sun"}, object["tags"])
+
+		if assert.IsType(t, &multipart.FileHeader{}, object["avatar"]) {
+			assert.Equal(t, "PNG", readFile(t, object["avatar"].(*multipart.FileHeader)))
+		}
+
+		if assert.IsType(t, []interface{}{}, object["docs"]) {
+			assert.Equal(t, 2, len(object["docs"].([]interface{})))
+		}
+
+	}
+
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+
+	codec := new(MultipartCodec)
+	var object upload
+
+	if assert.NoError(t, codec.Unmarshal(testBody(t), &object)) {
+
+		assert.Equal(t, "Holiday", object.Title)
+		assert.Equal(t, []string{"beach", "sun"}, object.Tags)
+
+		if assert.NotNil(t, object.Avatar) {
+			assert.Equal(t, "PNG", readFile(t, object.Avatar))
+		}
+
+		if assert.Equal(t, 2, len(object.Docs)) {
+			assert.Equal(t, "one", readFile(t, object.Docs[0]))
+			assert.Equal(t, "two", readFile(t, object.Docs[1]))
+		}
+
+	}
+
+}
+
+func TestUnmarshal_SpillToDisk(t *testing.T) {
+
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+
+	codec := &MultipartCodec{MaxMemory: 1}
+	var object multipart.Form
+
+	if assert.NoError(t, codec.Unmarshal(testBody(t), &object)) {
+		assert.Equal(t, "PNG", readFile(t, object.File["avatar"][0]))
+		assert.NotEmpty(t, tempFiles(t, tempDir), "file parts should be stored in temporary files")
+		assert.NoError(t, object.RemoveAll())
+	}
+
+}
+
+func TestUnmarshal_NoTemporaryFiles(t *testing.T) {
+
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+
+	codec := &MultipartCodec{MaxMemory: 1}
+
+	var object map[string]interface{}
+	if assert.NoError(t, codec.Unmarshal(testBody(t), &object)) {
+		if assert.IsType(t, &multipart.FileHeader{}, object["avatar"]) {
+			assert.Equal(t, "PNG", readFile(t, object["avatar"].(*multipart.FileHeader)))
+		}
+	}
+
+	var structObject upload
+	if assert.NoError(t, codec.Unmarshal(testBody(t), &structObject)) {
+		assert.Equal(t, "two", readFile(t, structObject.Docs[1]))
+	}
+
+	assert.Empty(t, tempFiles(t, tempDir), "no temporary files should be left behind")
+
+}
+
+func TestUnmarshal_Limits(t *testing.T) {
+
+	data := testBody(t)
+	var object multipart.Form
+
+	codec := &MultipartCodec{MaxSize: int64(len(data) - 1)}
+	assert.Equal(t, ErrorTooLarge, codec.Unmarshal(data, &object))
+
+	codec = &MultipartCodec{MaxFileSize: 2}
+	err := codec.Unmarshal(data, &object)
+
+	if assert.IsType(t, &FileTooLargeError{}, err) {
+		assert.Equal(t, int64(3), err.(*FileTooLargeError).Size)
+		assert.Equal(t, int64(2), err.(*FileTooLargeError).Limit)
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	codec := new(MultipartCodec)
+	var object map[string]interface{}
+
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal(testBody(t), object))
+	assert.Equal(t, ErrorMissingBoundary, codec.Unmarshal([]byte("title=Holiday"), &object))
+
+	// the wrong boundary
+	codec = &MultipartCodec{Boundary: "other"}
+	assert.Error(t, codec.Unmarshal(testBody(t), &object))
+
+}
+
+func TestWithParameters(t *testing.T) {
+
+	codec := &MultipartCodec{MaxFileSize: 10}
+
+	parameterized := codec.WithParameters(map[string]string{"boundary": testBoundary})
+
+	if assert.IsType(t, &MultipartCodec{}, parameterized) {
+		assert.Equal(t, testBoundary, parameterized.(*MultipartCodec).Boundary)
+		assert.Equal(t, int64(10), parameterized.(*MultipartCodec).MaxFileSize)
+	}
+
+	assert.Equal(t, codec, codec.WithParameters(map[string]string{"charset": "utf-8"}))
+
+}
+
+func TestContentType(t *testing.T) {
+
+	codec := &MultipartCodec{Boundary: testBoundary}
+
+	assert.Equal(t, constants.ContentTypeMultipart, codec.ContentType())
+	assert.Equal(t, "", codec.FileExtension())
+	assert.False(t, codec.CanMarshalWithCallback())
+
+	assert.True(t, codec.ContentTypeSupported(constants.ContentTypeMultipart))
+	assert.False(t, codec.ContentTypeSupported(constants.ContentTypeForm))
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	f.Add(testBody(f))
+	f.Add([]byte("--testboundary\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nHoliday\r\n--testboundary--\r\n"))
+	f.Add([]byte("--testboundary\r\n"))
+
+	codec := &MultipartCodec{MaxMemory: 1 << 10, MaxFileSize: 1 << 10}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object map[string]interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
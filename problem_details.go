@@ -0,0 +1,148 @@
+package codecs
+
+import (
+	"errors"
+	"net/http"
+)
+
+const (
+	// ProblemDetailsDefaultType is the problem type used when no Type is specified,
+	// as defined by RFC 7807.
+	ProblemDetailsDefaultType string = "about:blank"
+)
+
+// problemDetailsMembers are the member names reserved by RFC 7807.  Extensions
+// are not allowed to override these.
+var problemDetailsMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// ProblemDetails describes an error in the format defined by RFC 7807
+// (Problem Details for HTTP APIs).
+//
+// ProblemDetails implements the Facade interface, so it can be marshalled by any
+// codec, and the error interface, so it can be returned from code that fails.
+type ProblemDetails struct {
+
+	// Type is a URI reference that identifies the problem type.  If empty,
+	// "about:blank" is assumed.
+	Type string
+
+	// Title is a short, human-readable summary of the problem type.  If empty,
+	// and Status is set, the standard HTTP status text is used.
+	Title string
+
+	// Status is the HTTP status code generated by the origin server for this
+	// occurrence of the problem.
+	Status int
+
+	// Detail is a human-readable explanation specific to this occurrence of the
+	// problem.
+	Detail string
+
+	// Instance is a URI reference that identifies the specific occurrence of
+	// the problem.
+	Instance string
+
+	// Extensions holds any additional members to include in the problem details.
+	Extensions map[string]interface{}
+}
+
+// ProblemDetailer is the interface errors should implement if they are able to
+// describe themselves as ProblemDetails.
+type ProblemDetailer interface {
+
+	// ProblemDetails gets the ProblemDetails that describe this error.
+	ProblemDetails() *ProblemDetails
+}
+
+// NewProblemDetails makes a new ProblemDetails with the specified status and detail.
+func NewProblemDetails(status int, detail string) *ProblemDetails {
+	return &ProblemDetails{Status: status, Detail: detail}
+}
+
+// ProblemDetailsFromError gets the ProblemDetails that describe the specified error.
+//
+// If the error is, or wraps, a *ProblemDetails, it is returned directly.  If it is,
+// or wraps, an error implementing the ProblemDetailer interface, its ProblemDetails
+// method is called.  Otherwise, the error is described as a 500 Internal Server
+// Error with no detail, so the text of internal errors is not given to clients.
+//
+// Returns nil if err is nil.
+func ProblemDetailsFromError(err error) *ProblemDetails {
+
+	if err == nil {
+		return nil
+	}
+
+	var problem *ProblemDetails
+	if errors.As(err, &problem) {
+		return problem
+	}
+
+	var detailer ProblemDetailer
+	if errors.As(err, &detailer) {
+		return detailer.ProblemDetails()
+	}
+
+	return NewProblemDetails(http.StatusInternalServerError, "")
+}
+
+// Error gets a string describing the problem.
+func (p *ProblemDetails) Error() string {
+
+	title := p.title()
+
+	if p.Detail == "" {
+		return "codecs: problem: " + title
+	}
+	if title == "" {
+		return "codecs: problem: " + p.Detail
+	}
+	return "codecs: problem: " + title + ": " + p.Detail
+}
+
+// PublicData gets the map of members that describe the problem, as defined by RFC 7807.
+//
+// Empty members are omitted, apart from type which defaults to "about:blank".
+// Extensions are included alongside the standard members, but cannot override them.
+func (p *ProblemDetails) PublicData(options map[string]interface{}) (interface{}, error) {
+
+	data := make(map[string]interface{})
+
+	// add the extensions first, so they cannot override the standard members
+	for k, v := range p.Extensions {
+		data[k] = v
+	}
+	for _, member := range problemDetailsMembers {
+		delete(data, member)
+	}
+
+	if p.Type == "" {
+		data["type"] = ProblemDetailsDefaultType
+	} else {
+		data["type"] = p.Type
+	}
+
+	if title := p.title(); title != "" {
+		data["title"] = title
+	}
+	if p.Status != 0 {
+		data["status"] = p.Status
+	}
+	if p.Detail != "" {
+		data["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		data["instance"] = p.Instance
+	}
+
+	return data, nil
+}
+
+// title gets the title of the problem, falling back to the HTTP status text if
+// no Title is set.
+func (p *ProblemDetails) title() string {
+	if p.Title != "" {
+		return p.Title
+	}
+	return http.StatusText(p.Status)
+}
@@ -0,0 +1,107 @@
+package codecs
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+type testProblemDetailerError struct{}
+
+func (e *testProblemDetailerError) Error() string {
+	return "teapot"
+}
+
+func (e *testProblemDetailerError) ProblemDetails() *ProblemDetails {
+	return NewProblemDetails(http.StatusTeapot, e.Error())
+}
+
+func TestProblemDetails_Interfaces(t *testing.T) {
+
+	assert.Implements(t, (*Facade)(nil), new(ProblemDetails), "ProblemDetails")
+	assert.Implements(t, (*error)(nil), new(ProblemDetails), "ProblemDetails")
+
+}
+
+func TestProblemDetails_PublicData(t *testing.T) {
+
+	problem := &ProblemDetails{
+		Type:     "https://example.com/probs/out-of-credit",
+		Title:    "You do not have enough credit.",
+		Status:   http.StatusForbidden,
+		Detail:   "Your current balance is 30, but that costs 50.",
+		Instance: "/account/12345/msgs/abc",
+		Extensions: map[string]interface{}{
+			"balance": 30,
+			"status":  "should not override",
+		},
+	}
+
+	public, err := PublicDataMap(problem, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://example.com/probs/out-of-credit", public.Get("type").Str())
+		assert.Equal(t, "You do not have enough credit.", public.Get("title").Str())
+		assert.Equal(t, http.StatusForbidden, public.Get("status").Int())
+		assert.Equal(t, "Your current balance is 30, but that costs 50.", public.Get("detail").Str())
+		assert.Equal(t, "/account/12345/msgs/abc", public.Get("instance").Str())
+		assert.Equal(t, 30, public.Get("balance").Int())
+	}
+
+}
+
+func TestProblemDetails_PublicData_Defaults(t *testing.T) {
+
+	public, err := PublicDataMap(NewProblemDetails(http.StatusNotFound, ""), nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, ProblemDetailsDefaultType, public.Get("type").Str())
+		assert.Equal(t, "Not Found", public.Get("title").Str())
+		assert.Equal(t, http.StatusNotFound, public.Get("status").Int())
+		assert.False(t, public.Has("detail"), "Empty detail should be omitted")
+		assert.False(t, public.Has("instance"), "Empty instance should be omitted")
+	}
+
+}
+
+func TestProblemDetails_Error(t *testing.T) {
+
+	assert.Equal(t, "codecs: problem: Not Found", NewProblemDetails(http.StatusNotFound, "").Error())
+	assert.Equal(t, "codecs: problem: Not Found: No such thing", NewProblemDetails(http.StatusNotFound, "No such thing").Error())
+	assert.Equal(t, "codecs: problem: No such thing", (&ProblemDetails{Detail: "No such thing"}).Error())
+
+}
+
+func TestProblemDetailsFromError(t *testing.T) {
+
+	assert.Nil(t, ProblemDetailsFromError(nil))
+
+	problem := NewProblemDetails(http.StatusBadRequest, "Bad")
+	assert.Equal(t, problem, ProblemDetailsFromError(problem), "ProblemDetails should be returned directly")
+
+	problem = ProblemDetailsFromError(new(testProblemDetailerError))
+	if assert.NotNil(t, problem) {
+		assert.Equal(t, http.StatusTeapot, problem.Status, "ProblemDetailer should be used")
+	}
+
+	problem = ProblemDetailsFromError(PublicDataTooMuchRecursion)
+	if assert.NotNil(t, problem) {
+		assert.Equal(t, http.StatusInternalServerError, problem.Status)
+		assert.Equal(t, "", problem.Detail, "the text of unknown errors should not be given to clients")
+		assert.Equal(t, "codecs: problem: Internal Server Error", problem.Error())
+	}
+
+}
+
+func TestProblemDetailsFromError_Wrapped(t *testing.T) {
+
+	problem := NewProblemDetails(http.StatusBadRequest, "Bad")
+	assert.Equal(t, problem, ProblemDetailsFromError(fmt.Errorf("decoding: %w", problem)))
+
+	wrapped := ProblemDetailsFromError(fmt.Errorf("handling: %w", new(testProblemDetailerError)))
+	if assert.NotNil(t, wrapped) {
+		assert.Equal(t, http.StatusTeapot, wrapped.Status)
+	}
+
+}
@@ -0,0 +1,10 @@
+// A codec for handling Protocol Buffers encoding and decoding.
+//
+// ProtobufCodec only works with proto.Message values.  Other values, such as the
+// maps produced by codecs.PublicData, cause an InvalidMessageError.
+//
+// JsonCodec marshals proto.Message values using the protobuf JSON mapping, and all
+// other values as plain JSON, so the same handler can serve protobuf to internal
+// services and JSON to browsers.  WebCodecService uses plain JSON by default;
+// call its EnableProtobufJsonCodec method to use JsonCodec instead.
+package protobuf
@@ -0,0 +1,23 @@
+package protobuf
+
+import (
+	"reflect"
+)
+
+// An InvalidMessageError describes a value passed to Marshal or Unmarshal that is
+// not a proto.Message.
+type InvalidMessageError struct {
+
+	// Func is the function the value was passed to.
+	Func string
+
+	// Type is the type of the value.
+	Type reflect.Type
+}
+
+func (e *InvalidMessageError) Error() string {
+	if e.Type == nil {
+		return "codecs: protobuf: " + e.Func + "(nil)"
+	}
+	return "codecs: protobuf: " + e.Func + "(" + e.Type.String() + "): " + e.Type.String() + " is not a proto.Message"
+}
@@ -0,0 +1,35 @@
+package protobuf
+
+import (
+	"github.com/stretchr/codecs/json"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// JsonCodec converts objects to and from JSON, using the protobuf JSON mapping
+// for proto.Message values.
+//
+// All other values are handled by the embedded json.JsonCodec.
+type JsonCodec struct {
+	json.JsonCodec
+}
+
+// Marshal converts an object to JSON.
+func (c *JsonCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	if message, ok := object.(proto.Message); ok {
+		return protojson.Marshal(message)
+	}
+
+	return c.JsonCodec.Marshal(object, options)
+}
+
+// Unmarshal converts JSON into an object.
+func (c *JsonCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	if message, ok := obj.(proto.Message); ok {
+		return protojson.Unmarshal(data, message)
+	}
+
+	return c.JsonCodec.Unmarshal(data, obj)
+}
@@ -0,0 +1,65 @@
+package protobuf
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"google.golang.org/protobuf/proto"
+	"reflect"
+)
+
+var validProtobufContentTypes = []string{
+	constants.ContentTypeProtobuf,
+	"application/protobuf",
+}
+
+// ProtobufCodec converts proto.Message values to and from the Protocol Buffers
+// binary wire format.
+type ProtobufCodec struct{}
+
+// Marshal converts a proto.Message to protobuf.
+//
+// Map fields are written in order of their keys, so the same message always
+// marshals to the same data.
+func (c *ProtobufCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	message, ok := object.(proto.Message)
+	if !ok {
+		return nil, &InvalidMessageError{"Marshal", reflect.TypeOf(object)}
+	}
+
+	return proto.MarshalOptions{Deterministic: true}.Marshal(message)
+}
+
+// Unmarshal converts protobuf into a proto.Message.
+func (c *ProtobufCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	message, ok := obj.(proto.Message)
+	if !ok {
+		return &InvalidMessageError{"Unmarshal", reflect.TypeOf(obj)}
+	}
+
+	return proto.Unmarshal(data, message)
+}
+
+// ContentType returns the content type for this codec.
+func (c *ProtobufCodec) ContentType() string {
+	return constants.ContentTypeProtobuf
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *ProtobufCodec) FileExtension() string {
+	return constants.FileExtensionProtobuf
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *ProtobufCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+func (c *ProtobufCodec) ContentTypeSupported(contentType string) bool {
+	for _, supportedType := range validProtobufContentTypes {
+		if supportedType == contentType {
+			return true
+		}
+	}
+	return contentType == c.ContentType()
+}
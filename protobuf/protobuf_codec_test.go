@@ -0,0 +1,158 @@
+package protobuf
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"testing"
+	"time"
+)
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(ProtobufCodec), "ProtobufCodec")
+	assert.Implements(t, (*codecs.ContentTypeMatcherCodec)(nil), new(ProtobufCodec), "ProtobufCodec")
+
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	codec := new(ProtobufCodec)
+	obj, _ := structpb.NewStruct(map[string]interface{}{"name": "Mat", "age": 30, "animals": []interface{}{"Dog", "Cat"}})
+
+	data, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+
+		expected, _ := proto.MarshalOptions{Deterministic: true}.Marshal(obj)
+		assert.Equal(t, expected, data)
+
+		object := new(structpb.Struct)
+
+		if assert.NoError(t, codec.Unmarshal(data, object)) {
+			assert.True(t, proto.Equal(obj, object))
+			assert.Equal(t, map[string]interface{}{"name": "Mat", "age": float64(30), "animals": []interface{}{"Dog", "Cat"}}, object.AsMap())
+		}
+
+	}
+
+}
+
+func TestMarshal_NotMessage(t *testing.T) {
+
+	codec := new(ProtobufCodec)
+
+	_, err := codec.Marshal(map[string]interface{}{"name": "Mat"}, nil)
+
+	if assert.IsType(t, &InvalidMessageError{}, err) {
+		assert.Equal(t, "codecs: protobuf: Marshal(map[string]interface {}): map[string]interface {} is not a proto.Message", err.Error())
+	}
+
+	_, err = codec.Marshal(nil, nil)
+
+	if assert.IsType(t, &InvalidMessageError{}, err) {
+		assert.Equal(t, "codecs: protobuf: Marshal(nil)", err.Error())
+	}
+
+}
+
+func TestUnmarshal_NotMessage(t *testing.T) {
+
+	codec := new(ProtobufCodec)
+	var object map[string]interface{}
+
+	err := codec.Unmarshal([]byte{}, &object)
+
+	if assert.IsType(t, &InvalidMessageError{}, err) {
+		assert.Equal(t, "Unmarshal", err.(*InvalidMessageError).Func)
+	}
+
+	assert.Error(t, codec.Unmarshal([]byte{0xff}, new(timestamppb.Timestamp)))
+
+}
+
+func TestContentType(t *testing.T) {
+
+	codec := new(ProtobufCodec)
+
+	assert.Equal(t, constants.ContentTypeProtobuf, codec.ContentType())
+	assert.Equal(t, constants.FileExtensionProtobuf, codec.FileExtension())
+	assert.False(t, codec.CanMarshalWithCallback())
+
+	assert.True(t, codec.ContentTypeSupported("application/x-protobuf"))
+	assert.True(t, codec.ContentTypeSupported("application/protobuf"))
+	assert.False(t, codec.ContentTypeSupported("application/json"))
+
+}
+
+func TestJsonCodec_Interface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(JsonCodec), "JsonCodec")
+	assert.Implements(t, (*codecs.ContentTypeMatcherCodec)(nil), new(JsonCodec), "JsonCodec")
+
+}
+
+func TestJsonCodec_Message(t *testing.T) {
+
+	codec := new(JsonCodec)
+	obj := timestamppb.New(time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC))
+
+	data, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+
+		assert.Equal(t, `"2013-03-20T12:00:00Z"`, string(data))
+
+		object := new(timestamppb.Timestamp)
+
+		if assert.NoError(t, codec.Unmarshal(data, object)) {
+			assert.True(t, proto.Equal(obj, object))
+		}
+
+	}
+
+}
+
+func TestJsonCodec_NotMessage(t *testing.T) {
+
+	codec := new(JsonCodec)
+
+	data, err := codec.Marshal(map[string]interface{}{"name": "Mat"}, nil)
+
+	if assert.NoError(t, err) {
+
+		assert.Equal(t, `{"name":"Mat"}`, string(data))
+
+		var object map[string]interface{}
+
+		if assert.NoError(t, codec.Unmarshal(data, &object)) {
+			assert.Equal(t, "Mat", object["name"])
+		}
+
+	}
+
+	assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+	assert.True(t, codec.ContentTypeSupported(constants.ContentTypeProblemJSON))
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	message, _ := structpb.NewStruct(map[string]interface{}{"name": "Mat", "age": 30, "tags": []interface{}{"a", "b"}})
+	data, err := new(ProtobufCodec).Marshal(message, nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Add(data)
+	f.Add([]byte{0x0a, 0xff, 0xff, 0xff, 0xff, 0x0f})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		new(ProtobufCodec).Unmarshal(data, new(structpb.Struct))
+		new(JsonCodec).Unmarshal(data, new(structpb.Struct))
+	})
+
+}
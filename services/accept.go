@@ -26,6 +26,16 @@ func NewAcceptEntry() *AcceptEntry {
 	return entry
 }
 
+// InvalidQualityError is the error for when the q parameter of an Accept header
+// entry is not a number from 0 to 1.
+type InvalidQualityError struct {
+	Quality string
+}
+
+func (e *InvalidQualityError) Error() string {
+	return "Quality " + e.Quality + " is not a number from 0 to 1."
+}
+
 // ParseAcceptEntry parses a single entry within an Accept header into
 // a *AcceptEntry value.
 func ParseAcceptEntry(accept string) (*AcceptEntry, error) {
@@ -42,6 +52,10 @@ func ParseAcceptEntry(accept string) (*AcceptEntry, error) {
 		if err != nil {
 			return nil, err
 		}
+		// NaN fails both comparisons, and would break the ordering of entries
+		if !(quality >= 0 && quality <= 1) {
+			return nil, &InvalidQualityError{qualityString}
+		}
 		entry.Quality = float32(quality)
 	}
 
@@ -176,3 +190,37 @@ func OrderAcceptHeader(accept string) ([]*AcceptEntry, error) {
 	}
 	return acceptTree.Flatten(), nil
 }
+
+// mediaRangeIncludes gets whether the media range of an Accept entry, such as
+// "text/*" or "*/*", includes the media type.
+func mediaRangeIncludes(mediaRange, mediaType string) bool {
+	switch {
+	case mediaRange == "*/*":
+		return true
+	case strings.HasSuffix(mediaRange, "/*"):
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*"))
+	}
+	return mediaRange == mediaType
+}
+
+// acceptEntryFor gets the most specific of the ordered entries that includes
+// the media type, which decides its quality, or nil if none includes it.  An
+// exact media type is more specific than "type/*", which is more specific than
+// "*/*".
+func acceptEntryFor(entries []*AcceptEntry, mediaType string) *AcceptEntry {
+
+	mediaType, _, _ = strings.Cut(strings.ToLower(mediaType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+
+	var found *AcceptEntry
+	for _, entry := range entries {
+		if !mediaRangeIncludes(entry.ContentType.MimeType, mediaType) {
+			continue
+		}
+		if found == nil || strings.Count(entry.ContentType.MimeType, "*") < strings.Count(found.ContentType.MimeType, "*") {
+			found = entry
+		}
+	}
+
+	return found
+}
@@ -23,6 +23,13 @@ func TestParseAccept_QualityParam(t *testing.T) {
 	assert.Equal(t, accept.Quality, expectedQuality)
 }
 
+func TestParseAccept_InvalidQuality(t *testing.T) {
+	for _, acceptString := range []string{"application/json; q=1.5", "application/json; q=-1", "application/json; q=NaN"} {
+		_, err := ParseAcceptEntry(acceptString)
+		assert.IsType(t, &InvalidQualityError{}, err, acceptString+" should not parse")
+	}
+}
+
 func TestAcceptEntry_Equal(t *testing.T) {
 	entryA := &AcceptEntry{
 		Quality:          1.0,
@@ -114,3 +121,30 @@ func TestOrderAcceptHeader_FlattenPerformance(t *testing.T) {
 			"Flatten should allocate exactly as much memory as it needs; failed header: "+testHeader)
 	}
 }
+
+func FuzzOrderAcceptHeader(f *testing.F) {
+
+	f.Add("application/json")
+	f.Add("application/xml; q=0.7, */*; q=0.1, text/*; q=0.1, application/json, text/xml; q=0.7")
+	f.Add("text/html;level=1;q=0.5, , ;q=x")
+	f.Add(`multipart/form-data; boundary="a,b"`)
+
+	f.Fuzz(func(t *testing.T, accept string) {
+
+		entries, err := OrderAcceptHeader(accept)
+		if err != nil {
+			return
+		}
+
+		for index, entry := range entries {
+			if entry == nil || entry.ContentType == nil {
+				t.Fatalf("entry %d of %q is nil", index, accept)
+			}
+			if index > 0 && entry.Quality > entries[index-1].Quality {
+				t.Fatalf("entry %d of %q has a higher quality than the one before it", index, accept)
+			}
+		}
+
+	})
+
+}
@@ -1,9 +1,14 @@
 package services
 
 import (
+	"github.com/stretchr/codecs/constants"
 	"strings"
 )
 
+// defaultCharset is the charset added to text content types, since codecs
+// produce UTF-8.
+const defaultCharset string = "utf-8"
+
 // ContentType represents a single content type, complete with
 // parameters, such as that passed in an HTTP Accept or Content-Type
 // header.
@@ -17,8 +22,13 @@ func (contentType *ContentType) AddParam(param string) {
 	if equal == -1 {
 		return
 	}
-	name := strings.TrimSpace(param[:equal])
+	// parameter names are case insensitive, but values (such as a
+	// multipart boundary) may not be
+	name := strings.ToLower(strings.TrimSpace(param[:equal]))
 	value := strings.TrimSpace(param[equal+1:])
+	if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+		value = value[1 : len(value)-1]
+	}
 	contentType.Parameters[name] = value
 }
 
@@ -26,7 +36,7 @@ func (contentType *ContentType) AddParam(param string) {
 // mimetype and parameters, returning the ContentType representing the
 // string.
 func ParseContentType(rawType string) (*ContentType, error) {
-	rawType = strings.TrimSpace(strings.ToLower(rawType))
+	rawType = strings.TrimSpace(rawType)
 	if len(rawType) == 0 {
 		return nil, nil
 	}
@@ -47,7 +57,7 @@ func ParseContentType(rawType string) (*ContentType, error) {
 			value = remaining[:end]
 		}
 		if remaining == rawType {
-			contentType.MimeType = value
+			contentType.MimeType = strings.TrimSpace(strings.ToLower(value))
 			if end != -1 {
 				contentType.Parameters = make(map[string]string)
 			}
@@ -58,3 +68,30 @@ func ParseContentType(rawType string) (*ContentType, error) {
 	}
 	return contentType, nil
 }
+
+// ContentTypeWithCharset gets the value of the Content-Type header for a content
+// type, adding the UTF-8 charset to text content types that have no parameters.
+func ContentTypeWithCharset(contentType string) string {
+
+	if strings.Contains(contentType, ";") || !isTextContentType(contentType) {
+		return contentType
+	}
+
+	return contentType + "; charset=" + defaultCharset
+}
+
+// isTextContentType gets whether the content type is a text format.
+func isTextContentType(contentType string) bool {
+
+	if strings.HasPrefix(contentType, "text/") || strings.HasSuffix(contentType, "+json") || strings.HasSuffix(contentType, "+xml") {
+		return true
+	}
+
+	switch contentType {
+	case constants.ContentTypeJSON, constants.ContentTypeYAML, constants.ContentTypeTOML, constants.ContentTypeExtendedJSON,
+		"application/javascript", "application/xml":
+		return true
+	}
+
+	return false
+}
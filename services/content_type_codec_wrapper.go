@@ -2,6 +2,7 @@ package services
 
 import (
 	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
 )
 
 // contentTypeCodecWrapper is a wrapper for a Codec.  It is used to
@@ -30,7 +31,7 @@ func (c *contentTypeCodecWrapper) Marshal(object interface{}, options map[string
 	if options == nil {
 		options = make(map[string]interface{})
 	}
-	options["matched_type"] = c.contentType
+	options[constants.OptionKeyMatchedType] = c.contentType
 	return c.codec.Marshal(object, options)
 }
 
@@ -46,6 +47,19 @@ func (c *contentTypeCodecWrapper) FileExtension() string {
 	return c.codec.FileExtension()
 }
 
+func (c *contentTypeCodecWrapper) FileExtensions() []string {
+	return codecs.FileExtensions(c.codec)
+}
+
 func (c *contentTypeCodecWrapper) CanMarshalWithCallback() bool {
 	return c.codec.CanMarshalWithCallback()
 }
+
+// ContentTypeSupported returns whether the wrapped codec supports the content
+// type.
+func (c *contentTypeCodecWrapper) ContentTypeSupported(contentType string) bool {
+	if matcher, ok := c.codec.(codecs.ContentTypeMatcherCodec); ok {
+		return matcher.ContentTypeSupported(contentType)
+	}
+	return contentType == c.codec.ContentType()
+}
@@ -43,3 +43,11 @@ func TestWrapCodec_Marshal(t *testing.T) {
 	assert.Equal(t, response, []byte(expectedResponse),
 		"The wrapped codec should add the matched content type to options on unmarshal")
 }
+
+func TestWrapCodec_ContentTypeSupported(t *testing.T) {
+	wrappedCodec := wrapCodecWithContentType(new(json.JsonCodec), "text/json").(codecs.ContentTypeMatcherCodec)
+
+	assert.True(t, wrappedCodec.ContentTypeSupported("application/problem+json"))
+	assert.True(t, wrappedCodec.ContentTypeSupported("application/json"))
+	assert.False(t, wrappedCodec.ContentTypeSupported("text/xml"))
+}
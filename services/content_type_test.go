@@ -2,6 +2,7 @@ package services
 
 import (
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -28,3 +29,44 @@ func TestParseContentType_WithParams(t *testing.T) {
 		assert.Equal(t, parsedValue, value)
 	}
 }
+
+func TestParseContentType_ParamCase(t *testing.T) {
+	contentType, err := ParseContentType("Multipart/Form-Data; Boundary=\"AaB03x\"")
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", contentType.MimeType)
+	assert.Equal(t, "AaB03x", contentType.Parameters["boundary"])
+}
+
+func TestContentTypeWithCharset(t *testing.T) {
+
+	assert.Equal(t, "application/json; charset=utf-8", ContentTypeWithCharset("application/json"))
+	assert.Equal(t, "text/csv; charset=utf-8", ContentTypeWithCharset("text/csv"))
+	assert.Equal(t, "application/problem+json; charset=utf-8", ContentTypeWithCharset("application/problem+json"))
+	assert.Equal(t, "application/yaml; charset=utf-8", ContentTypeWithCharset("application/yaml"))
+	assert.Equal(t, "text/plain; charset=iso-8859-1", ContentTypeWithCharset("text/plain; charset=iso-8859-1"))
+	assert.Equal(t, "application/cbor", ContentTypeWithCharset("application/cbor"))
+	assert.Equal(t, "multipart/form-data; boundary=abc", ContentTypeWithCharset("multipart/form-data; boundary=abc"))
+
+}
+
+func FuzzParseContentType(f *testing.F) {
+
+	f.Add("application/json")
+	f.Add("text/html; charset=utf-8")
+	f.Add(`multipart/form-data; boundary="--abc;def"; ; =x`)
+	f.Add(";")
+
+	f.Fuzz(func(t *testing.T, rawType string) {
+
+		contentType, err := ParseContentType(rawType)
+		if err != nil || contentType == nil {
+			return
+		}
+
+		if strings.Contains(contentType.MimeType, ";") {
+			t.Fatalf("the mime type of %q has parameters: %q", rawType, contentType.MimeType)
+		}
+
+	})
+
+}
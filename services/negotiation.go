@@ -0,0 +1,270 @@
+package services
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/compression"
+	"github.com/stretchr/codecs/constants"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// NegotiationSource describes what decided the codec chosen for a response.
+type NegotiationSource string
+
+const (
+	// SourceCallback means the codec was chosen because the request has a
+	// callback.
+	SourceCallback NegotiationSource = "callback"
+
+	// SourceExtension means the codec was chosen by the file extension.
+	SourceExtension NegotiationSource = "extension"
+
+	// SourceAccept means the codec was chosen by an entry in the Accept header.
+	SourceAccept NegotiationSource = "accept"
+
+	// SourceDefault means no codec was chosen by the request, so the first
+	// installed codec was used.
+	SourceDefault NegotiationSource = "default"
+)
+
+// RejectedCandidate describes something in a request that could have decided
+// the codec for the response, but did not.
+type RejectedCandidate struct {
+
+	// Source is where the candidate came from.
+	Source NegotiationSource
+
+	// Value is the candidate, such as the extension or the media type of an
+	// Accept entry.
+	Value string
+
+	// Reason explains why the candidate was rejected.
+	Reason string
+}
+
+// NegotiationResult describes the codec chosen for a response, and why it was
+// chosen.
+type NegotiationResult struct {
+
+	// Codec is the chosen codec.
+	Codec codecs.Codec
+
+	// MediaType is the media type the codec responds with.
+	MediaType string
+
+	// Parameters are the parameters of the matched Accept entry, other than q.
+	Parameters map[string]string
+
+	// AcceptEntry is the Accept entry that matched the codec, if the Accept
+	// header decided it.
+	AcceptEntry *AcceptEntry
+
+	// Quality is the quality of the matched Accept entry, or 1 if the Accept
+	// header did not decide the codec.
+	Quality float32
+
+	// Source is what decided the codec.
+	Source NegotiationSource
+
+	// Rejected lists the candidates that were considered before the codec was
+	// chosen, in order.
+	Rejected []RejectedCandidate
+
+	// Vary lists the request headers that the choice depended on, for the Vary
+	// response header.
+	Vary []string
+}
+
+// negotiator is implemented by codec services that can explain their choice of
+// codec, such as WebCodecService.
+type negotiator interface {
+	NegotiateCodecForResponding(accept, extension string, hasCallback bool) (*NegotiationResult, error)
+}
+
+// Negotiate chooses the codec for responding with the service, and returns a
+// NegotiationResult describing the choice.
+//
+// Services that cannot explain their choice (those without a
+// NegotiateCodecForResponding method) are asked for a codec with
+// GetCodecForResponding, and the source of the choice is inferred from the codec.
+func Negotiate(service CodecService, accept, extension string, hasCallback bool) (*NegotiationResult, error) {
+
+	if negotiator, ok := service.(negotiator); ok {
+		return negotiator.NegotiateCodecForResponding(accept, extension, hasCallback)
+	}
+
+	codec, err := service.GetCodecForResponding(accept, extension, hasCallback)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NegotiationResult{Quality: 1}
+
+	switch {
+	case hasCallback && codec.CanMarshalWithCallback():
+		return result.choose(SourceCallback, codec), nil
+	case codecs.HasFileExtension(codec, extension):
+		return result.choose(SourceExtension, codec), nil
+	case accept != "":
+		// the service may have used its first codec, if nothing in the header
+		// matched
+		if entries, err := OrderAcceptHeader(accept); err == nil {
+			if entry := acceptEntryFor(entries, codec.ContentType()); entry != nil && entry.Quality > 0 {
+				result.AcceptEntry = entry
+				result.Quality = entry.Quality
+				return result.choose(SourceAccept, codec), nil
+			}
+		}
+	}
+
+	return result.choose(SourceDefault, codec), nil
+}
+
+// Header gets the response headers that describe the result: the Content-Type
+// (see ContentType), the Vary header, the Content-Encoding of compressing codecs
+// and the Content-Location.
+//
+// The resourcePath is the path of the resource without any extension, followed
+// by the query if there is one, and is used for the Content-Location (see
+// ContentLocation).  The options are those the data was marshalled with, which
+// can hold the content type of the data.
+//
+// Accept-Charset is never listed in the Vary header, since codecs always
+// produce UTF-8.
+func (r *NegotiationResult) Header(resourcePath string, options map[string]interface{}) http.Header {
+
+	header := make(http.Header)
+	header.Set("Content-Type", r.ContentType(options))
+
+	if len(r.Vary) > 0 {
+		header.Set("Vary", strings.Join(r.Vary, ", "))
+	}
+
+	if encoder, ok := r.Codec.(compression.ContentEncoder); ok {
+		header.Set("Content-Encoding", encoder.ContentEncoding())
+	}
+
+	if location := r.ContentLocation(resourcePath); location != "" {
+		header.Set("Content-Location", location)
+	}
+
+	return header
+}
+
+// ContentType gets the value of the Content-Type header for the result.
+//
+// Codecs whose data needs a content type with parameters that change with each
+// call, such as the boundary of multipart/form-data, give it in the options
+// passed to Marshal (see constants.OptionKeyResponseContentType), so the options
+// should be those the data was marshalled with.  Otherwise, the media type is
+// given with the parameters of the matched Accept entry, other than the charset,
+// and the UTF-8 charset for text formats.
+func (r *NegotiationResult) ContentType(options map[string]interface{}) string {
+
+	if contentType, ok := options[constants.OptionKeyResponseContentType].(string); ok && contentType != "" {
+		return ContentTypeWithCharset(contentType)
+	}
+
+	parameters := make(map[string]string, len(r.Parameters)+1)
+	for name, value := range r.Parameters {
+		// codecs always produce UTF-8
+		if name != "charset" {
+			parameters[name] = value
+		}
+	}
+
+	if len(parameters) == 0 {
+		return ContentTypeWithCharset(r.MediaType)
+	}
+
+	if isTextContentType(r.MediaType) {
+		parameters["charset"] = defaultCharset
+	}
+
+	if contentType := mime.FormatMediaType(r.MediaType, parameters); contentType != "" {
+		return contentType
+	}
+
+	return ContentTypeWithCharset(r.MediaType)
+}
+
+// ContentLocation gets the canonical URL of the response, which is the path of
+// the resource followed by the chosen codec's extension, and the query of the
+// resourcePath if it has one.
+//
+// There is no Content-Location for responses with a callback, for codecs
+// without an extension, or if the last segment of the path is empty (as in "/"
+// or "/users/") or already has an extension (as in "/users.json").
+func (r *NegotiationResult) ContentLocation(resourcePath string) string {
+
+	if r.Source == SourceCallback {
+		return ""
+	}
+
+	resourcePath, query, hasQuery := strings.Cut(resourcePath, "?")
+
+	segment := resourcePath[strings.LastIndexByte(resourcePath, '/')+1:]
+	if segment == "" || path.Ext(segment) != "" {
+		return ""
+	}
+
+	extension := r.Codec.FileExtension()
+	if extension == "" {
+		return ""
+	}
+
+	if hasQuery {
+		return resourcePath + extension + "?" + query
+	}
+
+	return resourcePath + extension
+}
+
+// String gets a description of the result, for logging.
+func (r *NegotiationResult) String() string {
+
+	description := []string{"source=" + string(r.Source), "mediaType=" + r.MediaType}
+
+	if r.AcceptEntry != nil {
+		description = append(description, "quality="+strconv.FormatFloat(float64(r.Quality), 'g', -1, 32))
+	}
+
+	for _, rejected := range r.Rejected {
+		description = append(description, "rejected="+string(rejected.Source)+":"+rejected.Value+" ("+rejected.Reason+")")
+	}
+
+	return strings.Join(description, " ")
+}
+
+// reject adds a rejected candidate to the result.
+func (r *NegotiationResult) reject(source NegotiationSource, value, reason string) {
+	r.Rejected = append(r.Rejected, RejectedCandidate{source, value, reason})
+}
+
+// choose sets the codec chosen for the result.
+func (r *NegotiationResult) choose(source NegotiationSource, codec codecs.Codec) *NegotiationResult {
+	r.Source = source
+	r.Codec = codec
+	r.MediaType = codec.ContentType()
+
+	// a different Accept header could have chosen a different codec, unless
+	// the URL itself decided it
+	if source == SourceAccept || source == SourceDefault {
+		r.addVary("Accept")
+	}
+
+	return r
+}
+
+// addVary adds a request header to the Vary list, if it is not already listed.
+func (r *NegotiationResult) addVary(name string) {
+	for _, vary := range r.Vary {
+		if vary == name {
+			return
+		}
+	}
+	r.Vary = append(r.Vary, name)
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNegotiateCodecForResponding_Callback(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, err := service.NegotiateCodecForResponding(constants.ContentTypeXML, ".xml", true)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceCallback, result.Source)
+		assert.Equal(t, constants.ContentTypeJSONP, result.MediaType)
+		assert.Equal(t, float32(1), result.Quality)
+		assert.Nil(t, result.AcceptEntry)
+		assert.Empty(t, result.Rejected)
+	}
+
+}
+
+func TestNegotiateCodecForResponding_Extension(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, err := service.NegotiateCodecForResponding(constants.ContentTypeJSON, ".XML", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceExtension, result.Source)
+		assert.Equal(t, constants.ContentTypeXML, result.Codec.ContentType())
+	}
+
+	result, err = service.NegotiateCodecForResponding(constants.ContentTypeJSON, ".pdf", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceAccept, result.Source)
+		assert.Equal(t, []RejectedCandidate{{SourceExtension, ".pdf", "no codec has the extension"}}, result.Rejected)
+	}
+
+}
+
+func TestNegotiateCodecForResponding_Accept(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, err := service.NegotiateCodecForResponding("image/png, application/vnd.unknown;q=0.9, text/csv;q=0.8;header=present, */*;q=0.1", "", false)
+
+	if assert.NoError(t, err) {
+
+		assert.Equal(t, SourceAccept, result.Source)
+		assert.Equal(t, constants.ContentTypeCSV, result.MediaType)
+		assert.Equal(t, float32(0.8), result.Quality)
+		assert.Equal(t, map[string]string{"header": "present"}, result.Parameters)
+
+		if assert.NotNil(t, result.AcceptEntry) {
+			assert.Equal(t, constants.ContentTypeCSV, result.AcceptEntry.ContentType.MimeType)
+		}
+
+		if assert.Equal(t, 2, len(result.Rejected)) {
+			assert.Equal(t, "image/png", result.Rejected[0].Value)
+			assert.Equal(t, "application/vnd.unknown", result.Rejected[1].Value)
+		}
+
+		assert.Equal(t, "source=accept mediaType=text/csv quality=0.8 rejected=accept:image/png (no codec supports the media type) rejected=accept:application/vnd.unknown (no codec supports the media type)", result.String())
+
+	}
+
+	// the simple case
+	result, err = service.NegotiateCodecForResponding(constants.ContentTypeYAML, "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceAccept, result.Source)
+		assert.Equal(t, constants.ContentTypeYAML, result.MediaType)
+		assert.Equal(t, constants.ContentTypeYAML, result.AcceptEntry.ContentType.MimeType)
+		assert.Equal(t, float32(1), result.Quality)
+	}
+
+	_, err = service.NegotiateCodecForResponding("application/json;q=high", "", false)
+	assert.Error(t, err)
+
+}
+
+func TestNegotiateCodecForResponding_MediaRange(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, err := service.NegotiateCodecForResponding("text/*", "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceAccept, result.Source)
+		// JSONP needs a callback, so the first text codec without one is chosen
+		assert.Equal(t, constants.ContentTypeCSV, result.MediaType)
+		assert.Equal(t, "text/*", result.AcceptEntry.ContentType.MimeType)
+	}
+
+	result, err = service.NegotiateCodecForResponding("text/*, text/csv;q=0", "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceAccept, result.Source)
+		assert.Equal(t, constants.ContentTypeXML, result.MediaType)
+	}
+
+	result, err = service.NegotiateCodecForResponding("*/*;q=0.5", "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceAccept, result.Source)
+		assert.Equal(t, constants.ContentTypeJSON, result.MediaType)
+		assert.Equal(t, float32(0.5), result.Quality)
+	}
+
+	result, err = service.NegotiateCodecForResponding("image/*", "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceDefault, result.Source)
+		assert.Equal(t, []RejectedCandidate{{SourceAccept, "image/*", "no codec supports the media type"}}, result.Rejected)
+	}
+
+}
+
+func TestNegotiateCodecForResponding_Excluded(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, err := service.NegotiateCodecForResponding("application/json;q=0", "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceDefault, result.Source)
+		assert.Equal(t, []RejectedCandidate{{SourceAccept, "application/json", "the media type is excluded with q=0"}}, result.Rejected)
+	}
+
+	result, err = service.NegotiateCodecForResponding("*/*, application/json;q=0", "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceAccept, result.Source)
+		assert.NotEqual(t, constants.ContentTypeJSON, result.MediaType)
+	}
+
+}
+
+func TestNegotiateCodecForResponding_Default(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, err := service.NegotiateCodecForResponding("", "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceDefault, result.Source)
+		assert.Equal(t, service.Codecs()[0], result.Codec)
+		assert.Equal(t, "source=default mediaType=application/json", result.String())
+	}
+
+}
+
+func TestNegotiationResult_Vary(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, _ := service.NegotiateCodecForResponding(constants.ContentTypeXML, "", false)
+	assert.Equal(t, []string{"Accept"}, result.Vary)
+
+	result, _ = service.NegotiateCodecForResponding("", "", false)
+	assert.Equal(t, []string{"Accept"}, result.Vary)
+
+	// the URL decides these, so they do not vary
+	result, _ = service.NegotiateCodecForResponding(constants.ContentTypeXML, ".json", false)
+	assert.Empty(t, result.Vary)
+
+	result, _ = service.NegotiateCodecForResponding(constants.ContentTypeXML, "", true)
+	assert.Empty(t, result.Vary)
+
+	if assert.NoError(t, service.NegotiateEncoding(result, "")) {
+		assert.Equal(t, []string{"Accept-Encoding"}, result.Vary)
+	}
+
+}
+
+func TestNegotiationResult_Header(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, _ := service.NegotiateCodecForResponding(constants.ContentTypeJSON, "", false)
+
+	if assert.NoError(t, service.NegotiateEncoding(result, "gzip")) {
+
+		header := result.Header("/people/1", nil)
+
+		assert.Equal(t, "application/json; charset=utf-8", header.Get("Content-Type"))
+		assert.Equal(t, "Accept, Accept-Encoding", header.Get("Vary"))
+		assert.Equal(t, "gzip", header.Get("Content-Encoding"))
+		assert.Equal(t, "/people/1.json", header.Get("Content-Location"))
+
+	}
+
+	result, _ = service.NegotiateCodecForResponding(constants.ContentTypeMsgpack, ".msgpack", false)
+	header := result.Header("/people/1", nil)
+
+	assert.Equal(t, constants.ContentTypeMsgpack, header.Get("Content-Type"))
+	assert.Equal(t, "", header.Get("Vary"))
+	assert.Equal(t, "", header.Get("Content-Encoding"))
+	assert.Equal(t, "/people/1.msgpack", header.Get("Content-Location"))
+
+	result, _ = service.NegotiateCodecForResponding("", "", true)
+	assert.Equal(t, "", result.ContentLocation("/people/1"))
+
+	result, _ = service.NegotiateCodecForResponding(constants.ContentTypeForm, "", false)
+	assert.Equal(t, "", result.ContentLocation("/people/1"))
+	assert.Equal(t, "", result.Header("", nil).Get("Content-Location"))
+
+}
+
+func TestNegotiationResult_ContentLocation(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, _ := service.NegotiateCodecForResponding(constants.ContentTypeJSON, "", false)
+
+	assert.Equal(t, "/users.json", result.ContentLocation("/users"))
+	assert.Equal(t, "/users/1.json", result.ContentLocation("/users/1"))
+	assert.Equal(t, "users.json", result.ContentLocation("users"))
+
+	// no final segment, or one that already has an extension
+	assert.Equal(t, "", result.ContentLocation(""))
+	assert.Equal(t, "", result.ContentLocation("/"))
+	assert.Equal(t, "", result.ContentLocation("/users/"))
+	assert.Equal(t, "", result.ContentLocation("/users.json"))
+	assert.Equal(t, "", result.ContentLocation("/api/v1.2"))
+
+	// the query is kept, since it is part of the resource
+	assert.Equal(t, "/users.json?page=2", result.ContentLocation("/users?page=2"))
+	assert.Equal(t, "", result.ContentLocation("/users/?page=2"))
+	assert.Equal(t, "", result.ContentLocation("/users.json?page=2"))
+
+	assert.Equal(t, "", result.Header("/", nil).Get("Content-Location"))
+	assert.Equal(t, "", result.Header("/users/", nil).Get("Content-Location"))
+	assert.Equal(t, "", result.Header("/users.json", nil).Get("Content-Location"))
+
+}
+
+func TestNegotiationResult_ContentType(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	// the parameters of the Accept entry, other than the charset
+	result, _ := service.NegotiateCodecForResponding("text/csv; header=present; charset=latin1", "", false)
+	assert.Equal(t, "text/csv; charset=utf-8; header=present", result.ContentType(nil))
+	assert.Equal(t, "text/csv; charset=utf-8; header=present", result.Header("/people", nil).Get("Content-Type"))
+
+	result, _ = service.NegotiateCodecForResponding("application/json; charset=latin1", "", false)
+	assert.Equal(t, "application/json; charset=utf-8", result.ContentType(nil))
+
+	// the content type the data was marshalled with
+	result, _ = service.NegotiateCodecForResponding(constants.ContentTypeMultipart, "", false)
+	options := map[string]interface{}{constants.OptionKeyResponseContentType: constants.ContentTypeMultipart + "; boundary=abc"}
+	assert.Equal(t, constants.ContentTypeMultipart+"; boundary=abc", result.ContentType(options))
+	assert.Equal(t, constants.ContentTypeMultipart+"; boundary=abc", result.Header("/people", options).Get("Content-Type"))
+
+}
+
+func TestNegotiationResult_NegotiateEncoding_NotAcceptable(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	result, _ := service.NegotiateCodecForResponding(constants.ContentTypeJSON, "", false)
+	codec := result.Codec
+
+	assert.IsType(t, &EncodingNotAcceptableError{}, service.NegotiateEncoding(result, "*;q=0"))
+	assert.Equal(t, codec, result.Codec)
+
+}
+
+func TestNegotiate(t *testing.T) {
+
+	// services that cannot explain their choice
+	service := struct{ CodecService }{NewWebCodecService()}
+
+	for _, test := range []struct {
+		accept      string
+		extension   string
+		hasCallback bool
+		source      NegotiationSource
+	}{
+		{constants.ContentTypeXML, ".json", true, SourceCallback},
+		{constants.ContentTypeXML, ".json", false, SourceExtension},
+		{constants.ContentTypeXML, ".pdf", false, SourceAccept},
+		{"", "", false, SourceDefault},
+		{"text/*", "", false, SourceAccept},
+		{"image/png", "", false, SourceDefault},
+	} {
+
+		result, err := Negotiate(service, test.accept, test.extension, test.hasCallback)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, test.source, result.Source, test.accept+" "+test.extension)
+		}
+
+	}
+
+	_, err := Negotiate(service, "application/json;q=high", "", false)
+	assert.Error(t, err)
+
+	result, err := Negotiate(NewWebCodecService(), constants.ContentTypeYAML, "", false)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, SourceAccept, result.Source)
+		assert.NotNil(t, result.AcceptEntry)
+	}
+
+}
@@ -3,12 +3,22 @@ package services
 import (
 	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/bson"
+	"github.com/stretchr/codecs/cbor"
+	"github.com/stretchr/codecs/compression"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/codecs/csv"
+	"github.com/stretchr/codecs/form"
+	"github.com/stretchr/codecs/html"
 	"github.com/stretchr/codecs/json"
 	"github.com/stretchr/codecs/jsonp"
 	"github.com/stretchr/codecs/msgpack"
+	"github.com/stretchr/codecs/multipart"
+	"github.com/stretchr/codecs/protobuf"
+	"github.com/stretchr/codecs/text"
+	"github.com/stretchr/codecs/toml"
 	"github.com/stretchr/codecs/xml"
+	"github.com/stretchr/codecs/yaml"
+	"net/http"
 	"strings"
 )
 
@@ -20,22 +30,83 @@ func (e *ContentTypeNotSupportedError) Error() string {
 	return "Content type " + e.ContentType + " is not supported."
 }
 
+// ProblemDetails gets the ProblemDetails describing this error as a
+// 415 Unsupported Media Type problem.
+func (e *ContentTypeNotSupportedError) ProblemDetails() *codecs.ProblemDetails {
+	problem := codecs.NewProblemDetails(http.StatusUnsupportedMediaType, e.Error())
+	problem.Extensions = map[string]interface{}{"contentType": e.ContentType}
+	return problem
+}
+
+// ContentEncodingNotSupportedError is the error for when a request body is
+// compressed with a content-coding that no installed coding can decode.
+type ContentEncodingNotSupportedError struct {
+	ContentEncoding string
+}
+
+func (e *ContentEncodingNotSupportedError) Error() string {
+	return "Content encoding " + e.ContentEncoding + " is not supported."
+}
+
+// ProblemDetails gets the ProblemDetails describing this error as a
+// 415 Unsupported Media Type problem.
+func (e *ContentEncodingNotSupportedError) ProblemDetails() *codecs.ProblemDetails {
+	problem := codecs.NewProblemDetails(http.StatusUnsupportedMediaType, e.Error())
+	problem.Extensions = map[string]interface{}{"contentEncoding": e.ContentEncoding}
+	return problem
+}
+
+// EncodingNotAcceptableError is the error for when an Accept-Encoding header
+// excludes both the identity coding and every installed coding.
+type EncodingNotAcceptableError struct {
+	AcceptEncoding string
+}
+
+func (e *EncodingNotAcceptableError) Error() string {
+	return "No acceptable content encoding for " + e.AcceptEncoding + "."
+}
+
+// ProblemDetails gets the ProblemDetails describing this error as a
+// 406 Not Acceptable problem.
+func (e *EncodingNotAcceptableError) ProblemDetails() *codecs.ProblemDetails {
+	problem := codecs.NewProblemDetails(http.StatusNotAcceptable, e.Error())
+	problem.Extensions = map[string]interface{}{"acceptEncoding": e.AcceptEncoding}
+	return problem
+}
+
 // DefaultCodecs represents the list of Codecs that get added automatically by
 // a call to NewWebCodecService.
-var DefaultCodecs = []codecs.Codec{new(json.JsonCodec), new(jsonp.JsonPCodec), new(msgpack.MsgpackCodec), new(bson.BsonCodec), new(csv.CsvCodec), new(xml.SimpleXmlCodec)}
+var DefaultCodecs = []codecs.Codec{new(json.JsonCodec), new(jsonp.JsonPCodec), new(msgpack.MsgpackCodec), new(bson.BsonCodec), new(csv.CsvCodec), new(xml.SimpleXmlCodec), new(yaml.YamlCodec), new(toml.TomlCodec), new(cbor.CborCodec), new(protobuf.ProtobufCodec), new(form.FormCodec), new(multipart.MultipartCodec), new(text.TextCodec), new(html.HtmlCodec)}
+
+// DefaultCodings represents the list of content-codings that get added
+// automatically by a call to NewWebCodecService.  When a client accepts more than
+// one of them equally, the one listed first is used, whatever order the client
+// lists them in.
+var DefaultCodings = []compression.Coding{new(compression.GzipCoding), new(compression.BrotliCoding), new(compression.ZstdCoding), new(compression.DeflateCoding)}
 
 // WebCodecService represents the default implementation for providing access to the
 // currently installed web codecs.
 type WebCodecService struct {
 	// codecs holds the installed codecs for this service.
 	codecs []codecs.Codec
+
+	// codings holds the installed content-codings for this service.
+	codings []compression.Coding
+
+	// MaxDecompressedSize is the largest decompressed request body, in bytes,
+	// accepted by the codecs returned from GetCodecWithEncoding.  If zero,
+	// compression.DefaultMaxDecompressedSize is used.  Set it to
+	// compression.NoLimit to accept decompressed bodies of any size.
+	MaxDecompressedSize int64
 }
 
 // NewWebCodecService makes a new WebCodecService with the default codecs
 // added.
 func NewWebCodecService() *WebCodecService {
 	s := new(WebCodecService)
-	s.codecs = DefaultCodecs
+	// copy the defaults so that RemoveCodec cannot change DefaultCodecs
+	s.codecs = append([]codecs.Codec(nil), DefaultCodecs...)
+	s.codings = append([]compression.Coding(nil), DefaultCodings...)
 	return s
 }
 
@@ -44,6 +115,39 @@ func (s *WebCodecService) Codecs() []codecs.Codec {
 	return s.codecs
 }
 
+// EnableExtendedJsonCodec installs the MongoDB Extended JSON codec
+// (bson.ExtendedJsonCodec) in this service.
+//
+// The Extended JSON codec is not installed by default, and must be explicitly opted
+// into for each service.  Calling this more than once has no further effect.
+func (s *WebCodecService) EnableExtendedJsonCodec() {
+	for _, codec := range s.codecs {
+		if _, ok := codec.(*bson.ExtendedJsonCodec); ok {
+			return
+		}
+	}
+	s.AddCodec(new(bson.ExtendedJsonCodec))
+}
+
+// EnableProtobufJsonCodec makes this service marshal proto.Message values to JSON
+// with the protobuf JSON mapping, by replacing the installed json.JsonCodec with a
+// protobuf.JsonCodec.  All other values are still marshalled as plain JSON.
+//
+// The protobuf JSON mapping is not used by default, and must be explicitly opted
+// into for each service.  Calling this more than once has no further effect.
+func (s *WebCodecService) EnableProtobufJsonCodec() {
+	for i, codec := range s.codecs {
+		switch codec.(type) {
+		case *protobuf.JsonCodec:
+			return
+		case *json.JsonCodec:
+			s.codecs[i] = new(protobuf.JsonCodec)
+			return
+		}
+	}
+	s.AddCodec(new(protobuf.JsonCodec))
+}
+
 // AddCodec adds the specified codec to the installed codecs list.
 func (s *WebCodecService) AddCodec(codec codecs.Codec) {
 	s.codecs = append(s.codecs, codec)
@@ -58,6 +162,25 @@ func (s *WebCodecService) RemoveCodec(contentType string) {
 	}
 }
 
+// Codings gets all currently installed content-codings.
+func (s *WebCodecService) Codings() []compression.Coding {
+	return s.codings
+}
+
+// AddCoding adds the specified content-coding to the installed codings list.
+func (s *WebCodecService) AddCoding(coding compression.Coding) {
+	s.codings = append(s.codings, coding)
+}
+
+// RemoveCoding removes a content-coding from the list of codings by name.
+func (s *WebCodecService) RemoveCoding(name string) {
+	for i, v := range s.codings {
+		if v.Name() == name {
+			s.codings = append(s.codings[:i], s.codings[i+1:]...)
+		}
+	}
+}
+
 func (s *WebCodecService) assertCodecs() {
 	if len(s.codecs) == 0 {
 		panic("codecs: No codecs are installed - use AddCodec to add some or use NewWebCodecService for default codecs.")
@@ -70,34 +193,59 @@ func (s *WebCodecService) assertCodecs() {
 //
 // As of now, if hasCallback is true, the JSONP codec will be returned.
 // This may be changed if additional callback capable codecs are added.
+//
+// Accept entries are tried in order of preference.  Entries with q=0 exclude
+// their media type, and wildcard entries such as "text/*" choose the first
+// installed codec in their range.  If no entry matches, the first installed
+// codec is returned.
 func (s *WebCodecService) GetCodecForResponding(accept, extension string, hasCallback bool) (codecs.Codec, error) {
 
+	result, err := s.NegotiateCodecForResponding(accept, extension, hasCallback)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Codec, nil
+}
+
+// NegotiateCodecForResponding chooses the codec to use to respond in the same
+// way as GetCodecForResponding, and returns a NegotiationResult explaining why
+// it was chosen.
+func (s *WebCodecService) NegotiateCodecForResponding(accept, extension string, hasCallback bool) (*NegotiationResult, error) {
+
 	// make sure we have at least one codec
 	s.assertCodecs()
 
+	result := &NegotiationResult{Quality: 1}
+
 	if hasCallback {
 		for _, codec := range s.codecs {
 			if codec.CanMarshalWithCallback() {
-				return codec, nil
+				return result.choose(SourceCallback, codec), nil
 			}
 		}
+		result.reject(SourceCallback, "callback", "no codec can marshal with a callback")
 	}
 
 	if extension != "" {
 		for _, codec := range s.codecs {
-			if strings.ToLower(codec.FileExtension()) == strings.ToLower(extension) {
-				return codec, nil
+			if codecs.HasFileExtension(codec, extension) {
+				return result.choose(SourceExtension, codec), nil
 			}
 		}
+		result.reject(SourceExtension, extension, "no codec has the extension")
 	}
 
 	if accept != "" {
 		// Try the simple case first
 		if !(strings.ContainsRune(accept, ',') || strings.ContainsRune(accept, ';')) {
-			accept = strings.TrimSpace(accept)
-			codec, err := s.getCodecByMimeString(accept)
+			mime := strings.TrimSpace(accept)
+			codec, _ := s.getCodecByMimeString(mime)
 			if codec != nil {
-				return codec, err
+				entry := NewAcceptEntry()
+				entry.ContentType = &ContentType{MimeType: mime}
+				result.AcceptEntry = entry
+				return result.choose(SourceAccept, codec), nil
 			}
 		}
 
@@ -108,14 +256,35 @@ func (s *WebCodecService) GetCodecForResponding(accept, extension string, hasCal
 			return nil, err
 		}
 		for _, entry := range orderedAccept {
-			if codec, err := s.getCodecByContentType(entry.ContentType); err == nil {
-				return codec, nil
+			if entry.Quality <= 0 {
+				result.reject(SourceAccept, entry.ContentType.MimeType, "the media type is excluded with q=0")
+				continue
 			}
+			codec, err := s.getCodecByContentType(entry.ContentType)
+			if err != nil && strings.HasSuffix(entry.ContentType.MimeType, "/*") {
+				codec, err = s.getCodecByMediaRange(entry.ContentType.MimeType, orderedAccept)
+			}
+			if err == nil {
+				result.AcceptEntry = entry
+				result.Quality = entry.Quality
+				result.choose(SourceAccept, codec)
+				for name, value := range entry.ContentType.Parameters {
+					if name == "q" {
+						continue
+					}
+					if result.Parameters == nil {
+						result.Parameters = make(map[string]string)
+					}
+					result.Parameters[name] = value
+				}
+				return result, nil
+			}
+			result.reject(SourceAccept, entry.ContentType.MimeType, "no codec supports the media type")
 		}
 	}
 
 	// return the first installed codec by default
-	return s.codecs[0], nil
+	return result.choose(SourceDefault, s.codecs[0]), nil
 }
 
 // GetCodec gets the codec to use to interpret the request based on the
@@ -130,7 +299,187 @@ func (s *WebCodecService) GetCodec(contentType string) (codecs.Codec, error) {
 		return nil, err
 	}
 
-	return s.getCodecByContentType(parsedContentType)
+	codec, err := s.getCodecByContentType(parsedContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// give the codec the parameters it needs to unmarshal, such as a boundary
+	if parameterized, ok := codec.(codecs.ParameterizedCodec); ok && len(parsedContentType.Parameters) > 0 {
+		return parameterized.WithParameters(parsedContentType.Parameters), nil
+	}
+
+	return codec, nil
+}
+
+// GetCoding gets the content-coding to use to compress a response based on the
+// given Accept-Encoding string.
+//
+// The codings are ordered by their quality values in the same way as an Accept
+// header.  When more than one installed coding has the highest quality, the one
+// installed first is used, and installed codings are preferred to the identity
+// coding.  A nil coding means the response should not be compressed (the
+// identity coding), which is the case when the Accept-Encoding string is empty or
+// lists no installed coding.  An EncodingNotAcceptableError is returned if the
+// identity coding is excluded and no installed coding is acceptable.
+func (s *WebCodecService) GetCoding(acceptEncoding string) (compression.Coding, error) {
+
+	if strings.TrimSpace(acceptEncoding) == "" {
+		return nil, nil
+	}
+
+	orderedAccept, err := OrderAcceptHeader(acceptEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	// codings listed explicitly are not matched by "*"
+	listed := make(map[string]bool, len(orderedAccept))
+	identityExcluded := false
+
+	for _, entry := range orderedAccept {
+		name := entry.ContentType.MimeType
+		listed[name] = true
+		if entry.Quality <= 0 && (name == compression.CodingIdentity || (name == "*" && !listed[compression.CodingIdentity])) {
+			identityExcluded = true
+		}
+	}
+
+	// the entries are ordered by quality, so take those of equal quality together
+	for start := 0; start < len(orderedAccept) && orderedAccept[start].Quality > 0; {
+
+		end := start
+		accepted := make(map[string]bool)
+		for ; end < len(orderedAccept) && orderedAccept[end].Quality == orderedAccept[start].Quality; end++ {
+			accepted[orderedAccept[end].ContentType.MimeType] = true
+		}
+
+		// ties are broken by the order of the installed codings
+		for _, coding := range s.codings {
+			if accepted[coding.Name()] || (accepted["*"] && !listed[coding.Name()]) {
+				return coding, nil
+			}
+		}
+
+		if accepted[compression.CodingIdentity] || (accepted["*"] && !listed[compression.CodingIdentity]) {
+			return nil, nil
+		}
+
+		start = end
+	}
+
+	if identityExcluded {
+		return nil, &EncodingNotAcceptableError{acceptEncoding}
+	}
+
+	return nil, nil
+}
+
+// CompressCodec gets a codec that compresses the output of the codec with the
+// content-coding negotiated from the given Accept-Encoding string, or the codec
+// itself if the response should not be compressed.
+//
+// The returned codec implements compression.ContentEncoder when it compresses,
+// which gives the value for the response's Content-Encoding header.
+func (s *WebCodecService) CompressCodec(codec codecs.Codec, acceptEncoding string) (codecs.Codec, error) {
+
+	coding, err := s.GetCoding(acceptEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	if coding == nil {
+		return codec, nil
+	}
+
+	return compression.NewCompressingCodec(codec, coding), nil
+}
+
+// NegotiateEncoding compresses the codec chosen for the result with the
+// content-coding negotiated from the given Accept-Encoding string, in the same
+// way as CompressCodec, and adds Accept-Encoding to the result's Vary list.
+func (s *WebCodecService) NegotiateEncoding(result *NegotiationResult, acceptEncoding string) error {
+
+	result.addVary("Accept-Encoding")
+
+	codec, err := s.CompressCodec(result.Codec, acceptEncoding)
+	if err != nil {
+		return err
+	}
+
+	result.Codec = codec
+
+	return nil
+}
+
+// GetCodecWithEncoding gets the codec to use to interpret a request based on
+// the content type and the content encoding, so the request body is decompressed
+// before it is unmarshalled.
+//
+// The content encoding lists the codings in the order they were applied, as in
+// a Content-Encoding header.
+func (s *WebCodecService) GetCodecWithEncoding(contentType, contentEncoding string) (codecs.Codec, error) {
+
+	codec, err := s.GetCodec(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range strings.Split(contentEncoding, ",") {
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == compression.CodingIdentity {
+			continue
+		}
+
+		coding := s.getCodingByName(name)
+		if coding == nil {
+			return nil, &ContentEncodingNotSupportedError{name}
+		}
+
+		codec = &compression.CompressingCodec{Codec: codec, Coding: coding, MaxDecompressedSize: s.MaxDecompressedSize}
+
+	}
+
+	return codec, nil
+}
+
+// ParsePath splits a URL path into the path of the resource and the file
+// extension of an installed codec, so "/people/1.json" becomes "/people/1" and
+// ".json".
+//
+// Only the last segment of the path can have an extension.  If it has none, or
+// its extension is not recognised by any installed codec (such as the ".2" in
+// "/api/v1.2"), the whole path is returned with an empty extension.
+func (s *WebCodecService) ParsePath(urlPath string) (resourcePath, extension string) {
+
+	segmentStart := strings.LastIndexByte(urlPath, '/') + 1
+	dot := strings.LastIndexByte(urlPath, '.')
+
+	// the dot must be inside the last segment, after its first character
+	if dot <= segmentStart {
+		return urlPath, ""
+	}
+
+	extension = urlPath[dot:]
+	for _, codec := range s.codecs {
+		if codecs.HasFileExtension(codec, extension) {
+			return urlPath[:dot], extension
+		}
+	}
+
+	return urlPath, ""
+}
+
+// getCodingByName is a helper method to retrieve the installed content-coding
+// with the passed in name, or nil if there is none.
+func (s *WebCodecService) getCodingByName(name string) compression.Coding {
+	for _, coding := range s.codings {
+		if coding.Name() == name {
+			return coding
+		}
+	}
+	return nil
 }
 
 // getCodecByMimeString is a helper method to retrieve a codec that
@@ -165,6 +514,35 @@ func (s *WebCodecService) getCodecByMimeString(mime string) (codecs.Codec, error
 
 }
 
+// getCodecByMediaRange gets the first installed codec whose content type is in
+// the media range of an Accept entry (such as "text/*"), unless a more specific
+// entry excludes it with q=0.  Codecs that can marshal with a callback are
+// skipped, since they need one and the request has none.
+func (s *WebCodecService) getCodecByMediaRange(mediaRange string, entries []*AcceptEntry) (codecs.Codec, error) {
+
+	for _, codec := range s.codecs {
+
+		if codec.CanMarshalWithCallback() {
+			continue
+		}
+
+		contentType, _, _ := strings.Cut(strings.ToLower(codec.ContentType()), ";")
+		contentType = strings.TrimSpace(contentType)
+
+		if !mediaRangeIncludes(mediaRange, contentType) {
+			continue
+		}
+
+		if entry := acceptEntryFor(entries, contentType); entry != nil && entry.Quality <= 0 {
+			continue
+		}
+
+		return codec, nil
+	}
+
+	return nil, &ContentTypeNotSupportedError{mediaRange}
+}
+
 // getCodecByContentType is a helper method to retrieve a codec that
 // can handle the passed in *ContentType value.
 func (s *WebCodecService) getCodecByContentType(contentType *ContentType) (codecs.Codec, error) {
@@ -3,14 +3,19 @@ package services
 import (
 	"fmt"
 	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/compression"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/protobuf"
 	"github.com/stretchr/codecs/test"
 	"github.com/stretchr/objx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"strings"
 	"testing"
+	"time"
 )
 
 /*
@@ -339,3 +344,505 @@ func TestUnmarshalWithCodec_WithError(t *testing.T) {
 	mock.AssertExpectationsForObjects(t, testCodec.Mock)
 
 }
+
+func TestGetCodec_ProblemDetails(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, err := service.GetCodec(constants.ContentTypeProblemJSON)
+
+	if assert.NoError(t, err) && assert.NotNil(t, codec) {
+		assert.Equal(t, constants.ContentTypeProblemJSON, codec.ContentType())
+		assert.Equal(t, constants.FileExtensionJSON, codec.FileExtension())
+	}
+
+	codec, err = service.GetCodec(constants.ContentTypeProblemXML)
+
+	if assert.NoError(t, err) && assert.NotNil(t, codec) {
+		assert.Equal(t, constants.ContentTypeProblemXML, codec.ContentType())
+		assert.Equal(t, constants.FileExtensionXML, codec.FileExtension())
+	}
+
+}
+
+func TestGetCodec_CBOR(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, err := service.GetCodec(constants.ContentTypeCBOR)
+
+	if assert.NoError(t, err) && assert.NotNil(t, codec) {
+		assert.Equal(t, constants.ContentTypeCBOR, codec.ContentType())
+	}
+
+	codec, err = service.GetCodec("application/senml+cbor")
+
+	if assert.NoError(t, err) && assert.NotNil(t, codec) {
+		assert.Equal(t, "application/senml+cbor", codec.ContentType())
+		assert.Equal(t, constants.FileExtensionCBOR, codec.FileExtension())
+	}
+
+	codec, _ = service.GetCodecForResponding("application/cbor, application/json;q=0.5", "", false)
+
+	if assert.NotNil(t, codec) {
+		assert.Equal(t, constants.ContentTypeCBOR, codec.ContentType())
+	}
+
+}
+
+func TestMarshalWithCodec_Protobuf(t *testing.T) {
+
+	service := NewWebCodecService()
+	message := timestamppb.New(time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC))
+
+	codec, _ := service.GetCodecForResponding("application/x-protobuf", "", false)
+
+	if assert.NotNil(t, codec) {
+
+		assert.Equal(t, constants.ContentTypeProtobuf, codec.ContentType())
+
+		bytes, err := service.MarshalWithCodec(codec, message, nil)
+
+		if assert.NoError(t, err) {
+			expected, _ := proto.Marshal(message)
+			assert.Equal(t, expected, bytes)
+		}
+
+		_, err = service.MarshalWithCodec(codec, map[string]interface{}{"name": "Mat"}, nil)
+		assert.IsType(t, &protobuf.InvalidMessageError{}, err)
+
+	}
+
+}
+
+func TestEnableProtobufJsonCodec(t *testing.T) {
+
+	service := NewWebCodecService()
+	message := timestamppb.New(time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC))
+
+	// plain JSON is the default
+	assert.IsType(t, &json.JsonCodec{}, service.Codecs()[0])
+
+	service.EnableProtobufJsonCodec()
+	service.EnableProtobufJsonCodec()
+	assert.Equal(t, len(DefaultCodecs), len(service.Codecs()), "the JSON codec should be replaced")
+
+	assert.IsType(t, &protobuf.JsonCodec{}, service.Codecs()[0])
+
+	codec, _ := service.GetCodecForResponding(constants.ContentTypeJSON, "", false)
+
+	if assert.NotNil(t, codec) {
+
+		bytes, err := service.MarshalWithCodec(codec, message, nil)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, `"2013-03-20T12:00:00Z"`, string(bytes))
+		}
+
+		bytes, err = service.MarshalWithCodec(codec, map[string]interface{}{"name": "Mat"}, nil)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, `{"name":"Mat"}`, string(bytes))
+		}
+
+	}
+
+	// other services are not changed
+	assert.IsType(t, &json.JsonCodec{}, NewWebCodecService().Codecs()[0])
+
+}
+
+func TestUnmarshalWithCodec_Form(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, err := service.GetCodec("application/x-www-form-urlencoded; charset=UTF-8")
+
+	if assert.NoError(t, err) && assert.NotNil(t, codec) {
+
+		assert.Equal(t, constants.ContentTypeForm, codec.ContentType())
+
+		var object map[string]interface{}
+
+		if assert.NoError(t, service.UnmarshalWithCodec(codec, []byte("user[name]=Mat"), &object)) {
+			assert.Equal(t, "Mat", objx.New(object).Get("user.name").Str())
+		}
+
+	}
+
+}
+
+func TestGetCodec_Multipart(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, err := service.GetCodec("multipart/form-data; boundary=\"AaB03x\"")
+
+	if assert.NoError(t, err) && assert.NotNil(t, codec) {
+
+		assert.Equal(t, constants.ContentTypeMultipart, codec.ContentType())
+
+		data := "--AaB03x\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nHoliday\r\n--AaB03x--\r\n"
+		var object map[string]interface{}
+
+		if assert.NoError(t, service.UnmarshalWithCodec(codec, []byte(data), &object)) {
+			assert.Equal(t, "Holiday", object["title"])
+		}
+
+	}
+
+	// each body has its own boundary, given in the options
+	codec, _ = service.GetCodecForResponding(constants.ContentTypeMultipart, "", false)
+
+	if assert.NotNil(t, codec) {
+
+		assert.Equal(t, constants.ContentTypeMultipart, codec.ContentType())
+
+		var boundaries []string
+		for i := 0; i < 2; i++ {
+
+			options := make(map[string]interface{})
+			_, err := service.MarshalWithCodec(codec, map[string]interface{}{"title": "Holiday"}, options)
+
+			if assert.NoError(t, err) {
+				contentType, _ := ParseContentType(options[constants.OptionKeyResponseContentType].(string))
+				if assert.NotNil(t, contentType) {
+					assert.Equal(t, constants.ContentTypeMultipart, contentType.MimeType)
+					boundaries = append(boundaries, contentType.Parameters["boundary"])
+				}
+			}
+
+		}
+
+		if assert.Equal(t, 2, len(boundaries)) {
+			assert.NotEmpty(t, boundaries[0])
+			assert.NotEqual(t, boundaries[0], boundaries[1])
+		}
+
+	}
+
+	service.RemoveCodec(constants.ContentTypeMultipart)
+	_, err = service.GetCodec(constants.ContentTypeMultipart)
+	assert.IsType(t, &ContentTypeNotSupportedError{}, err)
+
+}
+
+func TestGetCodecForResponding_Browser(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, _ := service.GetCodecForResponding("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "", false)
+
+	if assert.NotNil(t, codec) {
+		assert.Equal(t, constants.ContentTypeHTML, codec.ContentType())
+	}
+
+	codec, _ = service.GetCodecForResponding("text/plain", "", false)
+
+	if assert.NotNil(t, codec) {
+		assert.Equal(t, constants.ContentTypeText, codec.ContentType())
+	}
+
+}
+
+func TestGetCodecForResponding_ProblemDetails(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	codec, _ := service.GetCodecForResponding("application/problem+xml, application/problem+json;q=0.5", "", false)
+
+	if assert.NotNil(t, codec) {
+		assert.Equal(t, constants.ContentTypeProblemXML, codec.ContentType())
+	}
+
+	codec, _ = service.GetCodecForResponding(constants.ContentTypeProblemJSON, "", false)
+
+	if assert.NotNil(t, codec) {
+		assert.Equal(t, constants.ContentTypeProblemJSON, codec.ContentType())
+	}
+
+}
+
+func TestMarshalWithCodec_ProblemDetails(t *testing.T) {
+
+	service := NewWebCodecService()
+	problem := codecs.NewProblemDetails(404, "No such thing")
+
+	for _, codec := range service.Codecs() {
+
+		// protobuf can only marshal proto.Message values
+		if _, ok := codec.(*protobuf.ProtobufCodec); ok {
+			continue
+		}
+
+		options := map[string]interface{}{constants.OptionKeyClientCallback: "callback"}
+		bytes, err := service.MarshalWithCodec(codec, problem, options)
+
+		if assert.NoError(t, err, codec.ContentType()) {
+			assert.NotEmpty(t, bytes, codec.ContentType())
+		}
+
+	}
+
+	codec, _ := service.GetCodecForResponding(constants.ContentTypeProblemJSON, "", false)
+	bytes, err := service.MarshalWithCodec(codec, problem, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"detail":"No such thing","status":404,"title":"Not Found","type":"about:blank"}`, string(bytes))
+	}
+
+	codec, _ = service.GetCodecForResponding(constants.ContentTypeProblemXML, "", false)
+	bytes, err = service.MarshalWithCodec(codec, problem, nil)
+
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(bytes), `<problem xmlns="urn:ietf:rfc:7807">`)
+		assert.Contains(t, string(bytes), `</problem>`)
+	}
+
+}
+
+func TestContentTypeNotSupportedError_ProblemDetails(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	_, err := service.GetCodec("application/vnd.unknown")
+
+	problem := codecs.ProblemDetailsFromError(err)
+
+	if assert.NotNil(t, problem) {
+		assert.Equal(t, 415, problem.Status)
+		assert.Equal(t, "application/vnd.unknown", problem.Extensions["contentType"])
+	}
+
+}
+
+func TestEnableExtendedJsonCodec(t *testing.T) {
+
+	service := NewWebCodecService()
+	_, err := service.GetCodec(constants.ContentTypeExtendedJSON)
+	assert.IsType(t, &ContentTypeNotSupportedError{}, err, "Extended JSON should not be installed by default")
+
+	service.EnableExtendedJsonCodec()
+	service.EnableExtendedJsonCodec()
+	assert.Equal(t, len(DefaultCodecs)+1, len(service.Codecs()), "Extended JSON should only be added once")
+
+	codec, err := service.GetCodec(constants.ContentTypeExtendedJSON)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeExtendedJSON, codec.ContentType())
+	}
+
+	// other services are not changed
+	_, err = NewWebCodecService().GetCodec(constants.ContentTypeExtendedJSON)
+	assert.IsType(t, &ContentTypeNotSupportedError{}, err)
+
+}
+
+func TestAddRemoveCoding(t *testing.T) {
+
+	service := NewWebCodecService()
+	assert.Equal(t, len(DefaultCodings), len(service.Codings()))
+
+	service.RemoveCoding(compression.CodingGzip)
+	assert.Equal(t, len(DefaultCodings)-1, len(service.Codings()))
+	assert.Equal(t, compression.CodingGzip, DefaultCodings[0].Name(), "RemoveCoding should not change DefaultCodings")
+
+	service.AddCoding(new(compression.GzipCoding))
+	assert.Equal(t, len(DefaultCodings), len(service.Codings()))
+
+}
+
+func TestGetCoding(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	name := func(acceptEncoding string) string {
+		coding, err := service.GetCoding(acceptEncoding)
+		if !assert.NoError(t, err, acceptEncoding) || coding == nil {
+			return compression.CodingIdentity
+		}
+		return coding.Name()
+	}
+
+	assert.Equal(t, "identity", name(""))
+	assert.Equal(t, "gzip", name("gzip"))
+	assert.Equal(t, "br", name("GZIP;q=0.5, br"))
+	assert.Equal(t, "gzip", name("gzip, deflate, br, zstd"))
+	assert.Equal(t, "zstd", name("zstd, gzip;q=0.9"))
+	assert.Equal(t, "deflate", name("gzip;q=0, deflate;q=0.1"))
+	assert.Equal(t, "identity", name("compress"))
+	assert.Equal(t, "identity", name("identity, gzip;q=0.5"))
+	assert.Equal(t, "gzip", name("*"))
+	assert.Equal(t, "br", name("gzip;q=0, *"))
+	assert.Equal(t, "zstd", name("zstd, *;q=0"))
+
+	_, err := service.GetCoding("compress, identity;q=0")
+	assert.IsType(t, &EncodingNotAcceptableError{}, err)
+
+	_, err = service.GetCoding("*;q=0")
+	assert.IsType(t, &EncodingNotAcceptableError{}, err)
+
+	// identity listed explicitly is still acceptable
+	assert.Equal(t, "identity", name("identity;q=0.5, *;q=0"))
+
+	// ties are broken by the order of the installed codings, not the client's
+	assert.Equal(t, "gzip", name("br, gzip"))
+	assert.Equal(t, "gzip", name("br;q=1, gzip;q=1"))
+	assert.Equal(t, "gzip", name("zstd, gzip"))
+	assert.Equal(t, "br", name("identity, br"))
+	assert.Equal(t, "br", name("deflate;q=0.5, zstd;q=0.5, br;q=0.5"))
+
+	service.RemoveCoding(compression.CodingGzip)
+	service.AddCoding(new(compression.GzipCoding))
+	assert.Equal(t, "br", name("gzip, br"))
+
+	problem := codecs.ProblemDetailsFromError(&EncodingNotAcceptableError{"compress"})
+
+	if assert.NotNil(t, problem) {
+		assert.Equal(t, 406, problem.Status)
+	}
+
+}
+
+func TestCompressCodec(t *testing.T) {
+
+	service := NewWebCodecService()
+	jsonCodec := new(json.JsonCodec)
+
+	codec, err := service.CompressCodec(jsonCodec, "")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, jsonCodec, codec)
+	}
+
+	codec, err = service.CompressCodec(jsonCodec, "br;q=0.8, gzip")
+
+	if assert.NoError(t, err) && assert.Implements(t, (*compression.ContentEncoder)(nil), codec) {
+
+		assert.Equal(t, "gzip", codec.(compression.ContentEncoder).ContentEncoding())
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+
+		data, err := service.MarshalWithCodec(codec, map[string]interface{}{"name": "Mat"}, nil)
+
+		if assert.NoError(t, err) {
+
+			decoder, err := service.GetCodecWithEncoding(constants.ContentTypeJSON, "gzip")
+
+			if assert.NoError(t, err) {
+				var object map[string]interface{}
+				if assert.NoError(t, service.UnmarshalWithCodec(decoder, data, &object)) {
+					assert.Equal(t, "Mat", object["name"])
+				}
+			}
+
+		}
+
+	}
+
+	_, err = service.CompressCodec(jsonCodec, "*;q=0")
+	assert.IsType(t, &EncodingNotAcceptableError{}, err)
+
+}
+
+func TestGetCodecWithEncoding(t *testing.T) {
+
+	service := NewWebCodecService()
+	service.MaxDecompressedSize = 1000
+
+	// applied in order: gzip then br
+	encoder := compression.NewCompressingCodec(compression.NewCompressingCodec(new(json.JsonCodec), new(compression.GzipCoding)), new(compression.BrotliCoding))
+	data, err := encoder.Marshal(map[string]interface{}{"name": "Mat"}, nil)
+
+	if assert.NoError(t, err) {
+
+		codec, err := service.GetCodecWithEncoding(constants.ContentTypeJSON, "gzip, BR")
+
+		if assert.NoError(t, err) {
+
+			assert.Equal(t, "gzip, br", codec.(compression.ContentEncoder).ContentEncoding())
+			assert.Equal(t, int64(1000), codec.(*compression.CompressingCodec).MaxDecompressedSize)
+
+			var object map[string]interface{}
+			if assert.NoError(t, codec.Unmarshal(data, &object)) {
+				assert.Equal(t, "Mat", object["name"])
+			}
+
+		}
+
+	}
+
+	codec, err := service.GetCodecWithEncoding(constants.ContentTypeJSON, "identity")
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, constants.ContentTypeJSON, codec.ContentType())
+		_, compressed := codec.(compression.ContentEncoder)
+		assert.False(t, compressed)
+	}
+
+	_, err = service.GetCodecWithEncoding(constants.ContentTypeJSON, "compress")
+
+	if assert.IsType(t, &ContentEncodingNotSupportedError{}, err) {
+		problem := codecs.ProblemDetailsFromError(err)
+		assert.Equal(t, 415, problem.Status)
+		assert.Equal(t, "compress", problem.Extensions["contentEncoding"])
+	}
+
+	_, err = service.GetCodecWithEncoding("application/vnd.unknown", "gzip")
+	assert.IsType(t, &ContentTypeNotSupportedError{}, err)
+
+}
+
+func TestGetCodecForResponding_FileExtensions(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	for extension, contentType := range map[string]string{
+		".yaml": constants.ContentTypeYAML,
+		".yml":  constants.ContentTypeYAML,
+		".YML":  constants.ContentTypeYAML,
+		".html": constants.ContentTypeHTML,
+		".htm":  constants.ContentTypeHTML,
+	} {
+
+		codec, err := service.GetCodecForResponding(constants.ContentTypeJSON, extension, false)
+
+		if assert.NoError(t, err, extension) {
+			assert.Equal(t, contentType, codec.ContentType(), extension)
+		}
+
+	}
+
+}
+
+func TestParsePath(t *testing.T) {
+
+	service := NewWebCodecService()
+
+	for _, test := range []struct {
+		urlPath      string
+		resourcePath string
+		extension    string
+	}{
+		{"/people/1.json", "/people/1", ".json"},
+		{"/people/1.YML", "/people/1", ".YML"},
+		{"/people.yaml", "/people", ".yaml"},
+		{"/people/1", "/people/1", ""},
+		{"/archive.tar.xml", "/archive.tar", ".xml"},
+		{"/files/report.pdf", "/files/report.pdf", ""},
+		{"/api/v1.2", "/api/v1.2", ""},
+		{"/api/v1.2/people", "/api/v1.2/people", ""},
+		{"/api/v1.json/people", "/api/v1.json/people", ""},
+		{"/people/.json", "/people/.json", ""},
+		{"/people.json/", "/people.json/", ""},
+		{"people.csv", "people", ".csv"},
+		{"", "", ""},
+	} {
+
+		resourcePath, extension := service.ParsePath(test.urlPath)
+
+		assert.Equal(t, test.resourcePath, resourcePath, test.urlPath)
+		assert.Equal(t, test.extension, extension, test.urlPath)
+
+	}
+
+}
@@ -1,2 +1,6 @@
 // test package provides object useful for testing code that relies on Codecs.
+//
+// TestCodec is a mock, for checking exactly how a codec is used.  FakeCodec is
+// a codec with fixed behaviour that needs no setting up, and RecordingCodec
+// wraps a real codec to capture the calls made to it.
 package test
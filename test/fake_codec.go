@@ -0,0 +1,138 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FakeContentType is the content type of a FakeCodec that has no ContentTypes.
+const FakeContentType string = "application/x-fake"
+
+// FakeOutput is the result of one call to FakeCodec.Marshal.
+type FakeOutput struct {
+	Data []byte
+	Err  error
+}
+
+// FakeCodec is a codec with fixed behaviour, useful for testing code that relies
+// on codecs without setting up the expectations a TestCodec needs.
+//
+//	codec := &test.FakeCodec{
+//	    ContentTypes: []string{"application/vnd.fake", "text/fake"},
+//	    Extensions:   []string{".fake"},
+//	    Outputs:      []test.FakeOutput{{Data: []byte("first")}, {Err: errors.New("second")}},
+//	}
+//
+// It is safe to use from many goroutines at once.
+type FakeCodec struct {
+
+	// ContentTypes are the content types the codec supports.  The first is
+	// returned by ContentType.  If empty, FakeContentType is used.
+	ContentTypes []string
+
+	// Extensions are the file extensions of the codec, such as ".fake".  The
+	// first is returned by FileExtension.
+	Extensions []string
+
+	// Callback is whether the codec can marshal with a callback.
+	Callback bool
+
+	// Outputs are returned by Marshal, one per call, with the last repeated once
+	// they run out.  If empty, Marshal returns the object formatted with
+	// fmt.Sprint.
+	Outputs []FakeOutput
+
+	// UnmarshalError is returned by Unmarshal.  If nil, Unmarshal sets a
+	// *[]byte, *string or *interface{} to the data, and leaves other objects
+	// unchanged.
+	UnmarshalError error
+
+	mutex        sync.Mutex
+	marshalCalls int
+}
+
+// Marshal returns the next of the Outputs.
+func (c *FakeCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	c.mutex.Lock()
+	call := c.marshalCalls
+	c.marshalCalls++
+	c.mutex.Unlock()
+
+	if len(c.Outputs) == 0 {
+		return []byte(fmt.Sprint(object)), nil
+	}
+
+	if call >= len(c.Outputs) {
+		call = len(c.Outputs) - 1
+	}
+
+	return c.Outputs[call].Data, c.Outputs[call].Err
+}
+
+// Unmarshal returns the UnmarshalError, or puts the data into the object.
+func (c *FakeCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	if c.UnmarshalError != nil {
+		return c.UnmarshalError
+	}
+
+	switch target := obj.(type) {
+	case *[]byte:
+		*target = append([]byte(nil), data...)
+	case *string:
+		*target = string(data)
+	case *interface{}:
+		*target = string(data)
+	}
+
+	return nil
+}
+
+// ContentType gets the first of the ContentTypes.
+func (c *FakeCodec) ContentType() string {
+	if len(c.ContentTypes) == 0 {
+		return FakeContentType
+	}
+	return c.ContentTypes[0]
+}
+
+// ContentTypeSupported gets whether the content type is one of the
+// ContentTypes.
+func (c *FakeCodec) ContentTypeSupported(contentType string) bool {
+	if len(c.ContentTypes) == 0 {
+		return strings.EqualFold(contentType, FakeContentType)
+	}
+	for _, supportedType := range c.ContentTypes {
+		if strings.EqualFold(contentType, supportedType) {
+			return true
+		}
+	}
+	return false
+}
+
+// FileExtension gets the first of the Extensions, or "" if there are none.
+func (c *FakeCodec) FileExtension() string {
+	if len(c.Extensions) == 0 {
+		return ""
+	}
+	return c.Extensions[0]
+}
+
+// FileExtensions gets the Extensions.
+func (c *FakeCodec) FileExtensions() []string {
+	return c.Extensions
+}
+
+// CanMarshalWithCallback gets the Callback setting.
+func (c *FakeCodec) CanMarshalWithCallback() bool {
+	return c.Callback
+}
+
+// MarshalCalls gets the number of times Marshal has been called.
+func (c *FakeCodec) MarshalCalls() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.marshalCalls
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"errors"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/services"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFakeCodec_Interface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(FakeCodec), "FakeCodec")
+	assert.Implements(t, (*codecs.ContentTypeMatcherCodec)(nil), new(FakeCodec), "FakeCodec")
+	assert.Implements(t, (*codecs.FileExtensionsCodec)(nil), new(FakeCodec), "FakeCodec")
+
+}
+
+func TestFakeCodec_Defaults(t *testing.T) {
+
+	codec := new(FakeCodec)
+
+	assert.Equal(t, FakeContentType, codec.ContentType())
+	assert.True(t, codec.ContentTypeSupported(FakeContentType))
+	assert.Equal(t, "", codec.FileExtension())
+	assert.False(t, codec.CanMarshalWithCallback())
+
+	data, err := codec.Marshal(map[string]interface{}{"name": "Mat"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "map[name:Mat]", string(data))
+	}
+
+	var object interface{}
+	if assert.NoError(t, codec.Unmarshal([]byte("data"), &object)) {
+		assert.Equal(t, "data", object)
+	}
+
+}
+
+func TestFakeCodec_Outputs(t *testing.T) {
+
+	failure := errors.New("failure")
+	codec := &FakeCodec{Outputs: []FakeOutput{{Data: []byte("first")}, {Err: failure}}}
+
+	data, err := codec.Marshal(nil, nil)
+	assert.Equal(t, "first", string(data))
+	assert.NoError(t, err)
+
+	// the last output is repeated
+	for i := 0; i < 2; i++ {
+		_, err = codec.Marshal(nil, nil)
+		assert.Equal(t, failure, err)
+	}
+
+	assert.Equal(t, 3, codec.MarshalCalls())
+
+	codec.UnmarshalError = failure
+	assert.Equal(t, failure, codec.Unmarshal(nil, new(string)))
+
+}
+
+func TestFakeCodec_WebCodecService(t *testing.T) {
+
+	codec := &FakeCodec{
+		ContentTypes: []string{"application/vnd.fake", "text/fake"},
+		Extensions:   []string{".fake", ".fk"},
+		Callback:     true,
+	}
+
+	service := services.NewWebCodecService()
+	service.AddCodec(codec)
+
+	responding, err := service.GetCodecForResponding("text/fake", "", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "text/fake", responding.ContentType())
+	}
+
+	responding, err = service.GetCodecForResponding("", ".fk", false)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "application/vnd.fake", responding.ContentType())
+	}
+
+}
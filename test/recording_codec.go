@@ -0,0 +1,186 @@
+package test
+
+import (
+	"strings"
+	"sync"
+)
+
+const (
+	// MethodMarshal is the Method of a Call to Marshal.
+	MethodMarshal string = "Marshal"
+
+	// MethodUnmarshal is the Method of a Call to Unmarshal.
+	MethodUnmarshal string = "Unmarshal"
+)
+
+// codec is the codecs.Codec interface.  The codecs package uses this package in
+// its tests, so this package cannot import it.
+type codec interface {
+	Marshal(object interface{}, options map[string]interface{}) ([]byte, error)
+	Unmarshal(data []byte, obj interface{}) error
+	ContentType() string
+	FileExtension() string
+	CanMarshalWithCallback() bool
+}
+
+// Call is a call to Marshal or Unmarshal captured by a RecordingCodec.
+type Call struct {
+
+	// Method is MethodMarshal or MethodUnmarshal.
+	Method string
+
+	// Object is the object marshalled, or the object unmarshalled into.
+	Object interface{}
+
+	// Options are a copy of the options passed to Marshal.
+	Options map[string]interface{}
+
+	// Data is the data returned by Marshal, or passed to Unmarshal.
+	Data []byte
+
+	// Err is the error returned by the call.
+	Err error
+}
+
+// RecordingCodec wraps a real codec, capturing every call to Marshal and
+// Unmarshal so they can be checked in handler tests.
+//
+//	codec := test.NewRecordingCodec(new(json.JsonCodec))
+//	service := services.NewWebCodecService()
+//	service.AddCodec(codec)
+//
+//	// ... handle a request ...
+//
+//	calls := codec.MarshalCalls()
+//
+// It is safe to use from many goroutines at once.
+type RecordingCodec struct {
+
+	// Codec is the codec that calls are passed to.
+	Codec codec
+
+	mutex sync.Mutex
+	calls []Call
+}
+
+// NewRecordingCodec makes a RecordingCodec that passes calls to the codec.
+func NewRecordingCodec(wrapped codec) *RecordingCodec {
+	return &RecordingCodec{Codec: wrapped}
+}
+
+// Marshal marshals the object with the wrapped codec, and records the call.
+func (c *RecordingCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	// copy the options, since callers may change them later
+	var recordedOptions map[string]interface{}
+	if options != nil {
+		recordedOptions = make(map[string]interface{}, len(options))
+		for key, value := range options {
+			recordedOptions[key] = value
+		}
+	}
+
+	data, err := c.Codec.Marshal(object, options)
+
+	c.record(Call{Method: MethodMarshal, Object: object, Options: recordedOptions, Data: data, Err: err})
+
+	return data, err
+}
+
+// Unmarshal unmarshals the data with the wrapped codec, and records the call.
+func (c *RecordingCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	err := c.Codec.Unmarshal(data, obj)
+
+	c.record(Call{Method: MethodUnmarshal, Object: obj, Data: data, Err: err})
+
+	return err
+}
+
+// ContentType gets the content type of the wrapped codec.
+func (c *RecordingCodec) ContentType() string {
+	return c.Codec.ContentType()
+}
+
+// ContentTypeSupported gets whether the wrapped codec supports the content type,
+// using its own logic if it is a codecs.ContentTypeMatcherCodec.
+func (c *RecordingCodec) ContentTypeSupported(contentType string) bool {
+	if matcher, ok := c.Codec.(interface {
+		ContentTypeSupported(contentType string) bool
+	}); ok {
+		return matcher.ContentTypeSupported(contentType)
+	}
+	return strings.EqualFold(contentType, c.Codec.ContentType())
+}
+
+// FileExtension gets the file extension of the wrapped codec.
+func (c *RecordingCodec) FileExtension() string {
+	return c.Codec.FileExtension()
+}
+
+// FileExtensions gets the file extensions of the wrapped codec, in the same way
+// as codecs.FileExtensions.
+func (c *RecordingCodec) FileExtensions() []string {
+
+	if extensionsCodec, ok := c.Codec.(interface {
+		FileExtensions() []string
+	}); ok {
+		return extensionsCodec.FileExtensions()
+	}
+
+	if extension := c.Codec.FileExtension(); extension != "" {
+		return []string{extension}
+	}
+
+	return nil
+}
+
+// CanMarshalWithCallback gets whether the wrapped codec can marshal with a
+// callback.
+func (c *RecordingCodec) CanMarshalWithCallback() bool {
+	return c.Codec.CanMarshalWithCallback()
+}
+
+// Calls gets every call recorded, in order.
+func (c *RecordingCodec) Calls() []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// MarshalCalls gets the calls to Marshal, in order.
+func (c *RecordingCodec) MarshalCalls() []Call {
+	return c.callsTo(MethodMarshal)
+}
+
+// UnmarshalCalls gets the calls to Unmarshal, in order.
+func (c *RecordingCodec) UnmarshalCalls() []Call {
+	return c.callsTo(MethodUnmarshal)
+}
+
+// Reset forgets the recorded calls.
+func (c *RecordingCodec) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = nil
+}
+
+// record adds the call to the recorded calls.
+func (c *RecordingCodec) record(call Call) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+// callsTo gets the recorded calls to the method.
+func (c *RecordingCodec) callsTo(method string) []Call {
+
+	var calls []Call
+	for _, call := range c.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
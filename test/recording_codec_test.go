@@ -0,0 +1,96 @@
+package test
+
+import (
+	"errors"
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/codecs/json"
+	"github.com/stretchr/codecs/services"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRecordingCodec_Interface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(RecordingCodec), "RecordingCodec")
+	assert.Implements(t, (*codecs.ContentTypeMatcherCodec)(nil), new(RecordingCodec), "RecordingCodec")
+	assert.Implements(t, (*codecs.FileExtensionsCodec)(nil), new(RecordingCodec), "RecordingCodec")
+
+}
+
+func TestRecordingCodec_Calls(t *testing.T) {
+
+	codec := NewRecordingCodec(new(json.JsonCodec))
+	options := map[string]interface{}{"key": "value"}
+
+	data, err := codec.Marshal(map[string]interface{}{"name": "Mat"}, options)
+
+	if assert.NoError(t, err) {
+
+		// changes to the options after the call are not recorded
+		options["key"] = "changed"
+
+		var object map[string]interface{}
+		assert.NoError(t, codec.Unmarshal(data, &object))
+
+		calls := codec.Calls()
+		if assert.Equal(t, 2, len(calls)) {
+
+			assert.Equal(t, MethodMarshal, calls[0].Method)
+			assert.Equal(t, map[string]interface{}{"name": "Mat"}, calls[0].Object)
+			assert.Equal(t, map[string]interface{}{"key": "value"}, calls[0].Options)
+			assert.Equal(t, `{"name":"Mat"}`, string(calls[0].Data))
+
+			assert.Equal(t, MethodUnmarshal, calls[1].Method)
+			assert.Equal(t, &object, calls[1].Object)
+			assert.Equal(t, data, calls[1].Data)
+
+		}
+
+		assert.Equal(t, 1, len(codec.MarshalCalls()))
+		assert.Equal(t, 1, len(codec.UnmarshalCalls()))
+
+	}
+
+	codec.Reset()
+	assert.Equal(t, 0, len(codec.Calls()))
+
+}
+
+func TestRecordingCodec_Errors(t *testing.T) {
+
+	failure := errors.New("failure")
+	codec := NewRecordingCodec(&FakeCodec{Outputs: []FakeOutput{{Err: failure}}, UnmarshalError: failure})
+
+	codec.Marshal(nil, nil)
+	codec.Unmarshal(nil, nil)
+
+	for _, call := range codec.Calls() {
+		assert.Equal(t, failure, call.Err, call.Method)
+	}
+
+}
+
+func TestRecordingCodec_WebCodecService(t *testing.T) {
+
+	codec := NewRecordingCodec(new(json.JsonCodec))
+
+	service := services.NewWebCodecService()
+	service.RemoveCodec(constants.ContentTypeJSON)
+	service.AddCodec(codec)
+
+	assert.Equal(t, []string{".json"}, codec.FileExtensions())
+
+	responding, err := service.GetCodecForResponding(constants.ContentTypeJSON, "", false)
+
+	if assert.NoError(t, err) {
+
+		_, err := service.MarshalWithCodec(responding, map[string]interface{}{"name": "Mat"}, nil)
+
+		if assert.NoError(t, err) && assert.Equal(t, 1, len(codec.MarshalCalls())) {
+			assert.Equal(t, constants.ContentTypeJSON, codec.MarshalCalls()[0].Options[constants.OptionKeyMatchedType])
+		}
+
+	}
+
+}
@@ -0,0 +1,5 @@
+// A codec for handling plain text.
+//
+// Objects are formatted using fmt, or a Formatter of your choosing, so data can
+// be returned in a form people can read.
+package text
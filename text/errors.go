@@ -0,0 +1,33 @@
+package text
+
+import (
+	"reflect"
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: text: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: text: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: text: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// An UnmarshalTypeError describes an object passed to Unmarshal that text cannot
+// be unmarshalled into.  Text can be unmarshalled into strings, []byte, interface{}
+// and values implementing encoding.TextUnmarshaler.
+type UnmarshalTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "codecs: text: cannot unmarshal text into " + e.Type.String()
+}
@@ -0,0 +1,81 @@
+package text
+
+import (
+	"encoding"
+	"fmt"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+)
+
+// Formatter formats an object as text.
+type Formatter func(object interface{}) ([]byte, error)
+
+// DefaultFormatter formats objects using the %v verb of the fmt package.
+func DefaultFormatter(object interface{}) ([]byte, error) {
+	return []byte(fmt.Sprintf("%v", object)), nil
+}
+
+// TextCodec converts objects to and from plain text.
+type TextCodec struct {
+
+	// Formatter formats objects when marshalling.  If nil, DefaultFormatter is
+	// used.
+	Formatter Formatter
+}
+
+// Marshal converts an object to text.
+func (c *TextCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	if c.Formatter == nil {
+		return DefaultFormatter(object)
+	}
+
+	return c.Formatter(object)
+}
+
+// Unmarshal converts text into an object.
+//
+// The obj must be a non-nil pointer to a string, []byte, interface{} (which is
+// set to a string) or a value implementing encoding.TextUnmarshaler.
+func (c *TextCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	if textUnmarshaler, ok := obj.(encoding.TextUnmarshaler); ok {
+		return textUnmarshaler.UnmarshalText(data)
+	}
+
+	target := rv.Elem()
+
+	switch {
+	case target.Kind() == reflect.String:
+		target.SetString(string(data))
+	case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8:
+		target.SetBytes(append([]byte(nil), data...))
+	case target.Kind() == reflect.Interface && target.NumMethod() == 0:
+		target.Set(reflect.ValueOf(string(data)))
+	default:
+		return &UnmarshalTypeError{target.Type()}
+	}
+
+	return nil
+}
+
+// ContentType returns the content type for this codec.
+func (c *TextCodec) ContentType() string {
+	return constants.ContentTypeText
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *TextCodec) FileExtension() string {
+	return constants.FileExtensionText
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *TextCodec) CanMarshalWithCallback() bool {
+	return false
+}
@@ -0,0 +1,110 @@
+package text
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(TextCodec), "TextCodec")
+
+}
+
+func TestMarshal(t *testing.T) {
+
+	codec := new(TextCodec)
+
+	data, err := codec.Marshal(map[string]interface{}{"name": "Mat", "age": 30}, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "map[age:30 name:Mat]", string(data))
+	}
+
+	data, err = codec.Marshal("Hello", nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Hello", string(data))
+	}
+
+}
+
+func TestMarshal_Formatter(t *testing.T) {
+
+	codec := &TextCodec{Formatter: func(object interface{}) ([]byte, error) {
+		return []byte(strings.ToUpper(object.(string))), nil
+	}}
+
+	data, err := codec.Marshal("Hello", nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "HELLO", string(data))
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	codec := new(TextCodec)
+
+	var s string
+	if assert.NoError(t, codec.Unmarshal([]byte("Hello"), &s)) {
+		assert.Equal(t, "Hello", s)
+	}
+
+	var b []byte
+	if assert.NoError(t, codec.Unmarshal([]byte("Hello"), &b)) {
+		assert.Equal(t, []byte("Hello"), b)
+	}
+
+	var i interface{}
+	if assert.NoError(t, codec.Unmarshal([]byte("Hello"), &i)) {
+		assert.Equal(t, "Hello", i)
+	}
+
+	var ip net.IP
+	if assert.NoError(t, codec.Unmarshal([]byte("127.0.0.1"), &ip)) {
+		assert.Equal(t, "127.0.0.1", ip.String())
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	codec := new(TextCodec)
+
+	var s string
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal([]byte("Hello"), s))
+
+	var m map[string]interface{}
+	assert.IsType(t, &UnmarshalTypeError{}, codec.Unmarshal([]byte("Hello"), &m))
+
+}
+
+func TestContentType(t *testing.T) {
+
+	codec := new(TextCodec)
+
+	assert.Equal(t, constants.ContentTypeText, codec.ContentType())
+	assert.Equal(t, constants.FileExtensionText, codec.FileExtension())
+	assert.False(t, codec.CanMarshalWithCallback())
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(TextCodec)
+
+	f.Add([]byte("Hello"))
+	f.Add([]byte("\xff\xfe"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
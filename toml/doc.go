@@ -0,0 +1,9 @@
+// A codec for handling TOML encoding and decoding.
+//
+// TOML documents are always tables, so only maps (with string keys) and structs
+// can be marshalled at the top level.  Values that cannot be represented in TOML,
+// such as top-level arrays, arrays containing nil or arrays mixing values of
+// different types, cause Marshal to return an UnrepresentableValueError.
+//
+// TOML datetimes are marshalled from, and unmarshalled into, time.Time values.
+package toml
@@ -0,0 +1,51 @@
+package toml
+
+import (
+	"reflect"
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: toml: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: toml: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: toml: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// An UnrepresentableValueError describes a value passed to Marshal that cannot be
+// represented in TOML.
+type UnrepresentableValueError struct {
+
+	// Key is the dotted key of the value, or "" for the top-level value.
+	Key string
+
+	// Type is the type of the value.
+	Type reflect.Type
+
+	// Reason explains why the value cannot be represented.
+	Reason string
+}
+
+func (e *UnrepresentableValueError) Error() string {
+	if e.Key == "" {
+		return "codecs: toml: cannot represent top-level " + typeString(e.Type) + ": " + e.Reason
+	}
+	return "codecs: toml: cannot represent " + typeString(e.Type) + " at \"" + e.Key + "\": " + e.Reason
+}
+
+// typeString gets a description of the type for use in error messages.
+func typeString(t reflect.Type) string {
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
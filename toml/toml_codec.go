@@ -0,0 +1,226 @@
+package toml
+
+import (
+	"bytes"
+	"encoding"
+	tomlEncoding "github.com/BurntSushi/toml"
+	"github.com/stretchr/codecs/constants"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	typeTime          = reflect.TypeOf(time.Time{})
+	typeTextMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	typeTomlMarshaler = reflect.TypeOf((*tomlEncoding.Marshaler)(nil)).Elem()
+)
+
+// The TOML types that values are represented as, used to detect mixed arrays.
+const (
+	tomlString   string = "string"
+	tomlInteger  string = "integer"
+	tomlFloat    string = "float"
+	tomlBoolean  string = "boolean"
+	tomlDatetime string = "datetime"
+	tomlArray    string = "array"
+	tomlTable    string = "table"
+)
+
+// TomlCodec converts objects to and from TOML.
+type TomlCodec struct{}
+
+// Marshal converts an object to TOML.
+//
+// If the object cannot be represented in TOML, an UnrepresentableValueError is
+// returned.
+func (c *TomlCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	value := indirect(reflect.ValueOf(object))
+
+	if value.Kind() != reflect.Map && (value.Kind() != reflect.Struct || leafType(value) != "") {
+		return nil, &UnrepresentableValueError{Type: reflect.TypeOf(object), Reason: "TOML documents must be a map or struct"}
+	}
+
+	if err := checkValue("", value); err != nil {
+		return nil, err
+	}
+
+	byteBuffer := new(bytes.Buffer)
+
+	if err := tomlEncoding.NewEncoder(byteBuffer).Encode(object); err != nil {
+		return nil, err
+	}
+
+	return byteBuffer.Bytes(), nil
+}
+
+// Unmarshal converts TOML into an object.
+func (c *TomlCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	return tomlEncoding.Unmarshal(data, obj)
+}
+
+// ContentType returns the content type for this codec.
+func (c *TomlCodec) ContentType() string {
+	return constants.ContentTypeTOML
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *TomlCodec) FileExtension() string {
+	return constants.FileExtensionTOML
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *TomlCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+// checkValue checks that the value, and every value it contains, can be
+// represented in TOML.
+func checkValue(key string, value reflect.Value) error {
+
+	value = indirect(value)
+
+	if !value.IsValid() || leafType(value) != "" {
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+
+		if value.Type().Key().Kind() != reflect.String {
+			return &UnrepresentableValueError{Key: key, Type: value.Type(), Reason: "TOML keys must be strings"}
+		}
+
+		for _, mapKey := range value.MapKeys() {
+			if err := checkValue(joinKey(key, mapKey.String()), value.MapIndex(mapKey)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Struct:
+
+		valueType := value.Type()
+		for index := 0; index < valueType.NumField(); index++ {
+
+			field := valueType.Field(index)
+			if field.PkgPath != "" && !field.Anonymous {
+				continue
+			}
+
+			name := field.Name
+			if tag := field.Tag.Get("toml"); tag == "-" {
+				continue
+			} else if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+				name = tagName
+			}
+
+			if err := checkValue(joinKey(key, name), value.Field(index)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+
+		arrayType := ""
+		for index := 0; index < value.Len(); index++ {
+
+			itemKey := key + "[" + strconv.Itoa(index) + "]"
+			item := indirect(value.Index(index))
+
+			itemType := tomlTypeOf(item)
+			if itemType == "" {
+				return &UnrepresentableValueError{Key: itemKey, Type: value.Type(), Reason: "TOML arrays cannot contain nil"}
+			}
+			if arrayType == "" {
+				arrayType = itemType
+			} else if itemType != arrayType {
+				return &UnrepresentableValueError{Key: key, Type: value.Type(), Reason: "TOML arrays cannot mix " + arrayType + " and " + itemType + " values"}
+			}
+
+			if err := checkValue(itemKey, item); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return &UnrepresentableValueError{Key: key, Type: value.Type(), Reason: "TOML has no equivalent type"}
+	}
+
+	return nil
+}
+
+// tomlTypeOf gets the TOML type the value will be represented as, or "" if the
+// value is nil.
+func tomlTypeOf(value reflect.Value) string {
+
+	if !value.IsValid() {
+		return ""
+	}
+
+	if leaf := leafType(value); leaf != "" {
+		return leaf
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return tomlBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return tomlInteger
+	case reflect.Float32, reflect.Float64:
+		return tomlFloat
+	case reflect.String:
+		return tomlString
+	case reflect.Slice, reflect.Array:
+		return tomlArray
+	case reflect.Map, reflect.Struct:
+		return tomlTable
+	}
+
+	return value.Kind().String()
+}
+
+// leafType gets the TOML type of values that are marshalled as a whole rather
+// than by inspecting their contents, or "" for any other value.
+func leafType(value reflect.Value) string {
+
+	valueType := value.Type()
+
+	if valueType == typeTime {
+		return tomlDatetime
+	}
+	if valueType.Implements(typeTextMarshaler) || valueType.Implements(typeTomlMarshaler) {
+		return tomlString
+	}
+
+	return ""
+}
+
+// indirect follows pointers and interfaces until it reaches a value that is
+// neither, or is nil.
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// joinKey appends a key to a dotted key.
+func joinKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
@@ -0,0 +1,178 @@
+package toml
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/objx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var codec TomlCodec
+
+type settings struct {
+	Name    string    `toml:"name"`
+	Enabled bool      `toml:"enabled"`
+	Updated time.Time `toml:"updated"`
+	Ports   []int     `toml:"ports"`
+	Owner   struct {
+		Email string `toml:"email"`
+	} `toml:"owner"`
+}
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(TomlCodec), "TomlCodec")
+
+}
+
+func TestMarshal(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"name":  "Mat",
+		"age":   30,
+		"ports": []interface{}{8000, 8001},
+		"address": map[string]interface{}{
+			"city": "Boulder",
+		},
+	}
+
+	tomlData, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "age = 30\nname = \"Mat\"\nports = [8000, 8001]\n\n[address]\n  city = \"Boulder\"\n", string(tomlData))
+	}
+
+}
+
+func TestMarshal_ObjxMap(t *testing.T) {
+
+	tomlData, err := codec.Marshal(objx.MSI("name", "Mat"), nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "name = \"Mat\"\n", string(tomlData))
+	}
+
+}
+
+func TestMarshal_Struct(t *testing.T) {
+
+	obj := settings{Name: "Mat", Enabled: true, Updated: time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC), Ports: []int{8000}}
+	obj.Owner.Email = "mat@example.com"
+
+	tomlData, err := codec.Marshal(&obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "name = \"Mat\"\nenabled = true\nupdated = 2013-03-20T12:00:00Z\nports = [8000]\n\n[owner]\n  email = \"mat@example.com\"\n", string(tomlData))
+	}
+
+}
+
+func TestMarshal_Unrepresentable(t *testing.T) {
+
+	_, err := codec.Marshal([]interface{}{1, 2}, nil)
+
+	if assert.IsType(t, &UnrepresentableValueError{}, err) {
+		assert.Equal(t, "", err.(*UnrepresentableValueError).Key)
+		assert.Equal(t, "codecs: toml: cannot represent top-level []interface {}: TOML documents must be a map or struct", err.Error())
+	}
+
+	_, err = codec.Marshal(map[string]interface{}{"settings": map[string]interface{}{"values": []interface{}{1, "two"}}}, nil)
+
+	if assert.IsType(t, &UnrepresentableValueError{}, err) {
+		assert.Equal(t, "settings.values", err.(*UnrepresentableValueError).Key)
+		assert.Equal(t, "codecs: toml: cannot represent []interface {} at \"settings.values\": TOML arrays cannot mix integer and string values", err.Error())
+	}
+
+	_, err = codec.Marshal(map[string]interface{}{"values": []interface{}{1, nil}}, nil)
+
+	if assert.IsType(t, &UnrepresentableValueError{}, err) {
+		assert.Equal(t, "values[1]", err.(*UnrepresentableValueError).Key)
+	}
+
+	_, err = codec.Marshal(map[int]interface{}{1: "one"}, nil)
+	assert.IsType(t, &UnrepresentableValueError{}, err)
+
+	_, err = codec.Marshal(nil, nil)
+	assert.IsType(t, &UnrepresentableValueError{}, err)
+
+	_, err = codec.Marshal(time.Now(), nil)
+	assert.IsType(t, &UnrepresentableValueError{}, err)
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	tomlString := "name = \"Mat\"\nage = 30\nupdated = 2013-03-20T12:00:00Z\n\n[address]\ncity = \"Boulder\"\n\n[[pets]]\nname = \"Dog\"\n"
+	var object map[string]interface{}
+
+	if assert.NoError(t, codec.Unmarshal([]byte(tomlString), &object)) {
+		assert.Equal(t, "Mat", object["name"])
+		assert.Equal(t, int64(30), object["age"])
+		assert.Equal(t, map[string]interface{}{"city": "Boulder"}, object["address"])
+		assert.Equal(t, []map[string]interface{}{{"name": "Dog"}}, object["pets"])
+		if assert.IsType(t, time.Time{}, object["updated"]) {
+			assert.True(t, time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC).Equal(object["updated"].(time.Time)))
+		}
+	}
+
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+
+	tomlString := "name = \"Mat\"\nenabled = true\nupdated = 2013-03-20T05:00:00-07:00\nports = [8000, 8001]\n\n[owner]\nemail = \"mat@example.com\"\n"
+	var object settings
+
+	if assert.NoError(t, codec.Unmarshal([]byte(tomlString), &object)) {
+		assert.Equal(t, "Mat", object.Name)
+		assert.True(t, object.Enabled)
+		assert.True(t, time.Date(2013, 3, 20, 12, 0, 0, 0, time.UTC).Equal(object.Updated))
+		assert.Equal(t, []int{8000, 8001}, object.Ports)
+		assert.Equal(t, "mat@example.com", object.Owner.Email)
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var object map[string]interface{}
+
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal([]byte("name = \"Mat\""), object))
+	assert.Error(t, codec.Unmarshal([]byte("name = "), &object))
+
+}
+
+func TestResponseContentType(t *testing.T) {
+
+	assert.Equal(t, constants.ContentTypeTOML, codec.ContentType())
+
+}
+
+func TestFileExtension(t *testing.T) {
+
+	assert.Equal(t, constants.FileExtensionTOML, codec.FileExtension())
+
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+
+	assert.False(t, codec.CanMarshalWithCallback())
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(TomlCodec)
+
+	f.Add([]byte("name = \"Mat\"\nage = 30\n[address]\ncity = \"London\"\n"))
+	f.Add([]byte("[[objects]]\nname = \"Mat\"\n[[objects]]\nname = \"Tyler\"\n"))
+	f.Add([]byte("when = 2013-03-20T12:00:00Z\n"))
+	f.Add([]byte("a = [1, \"b\"]"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}
@@ -50,6 +50,15 @@
 //       </field2>
 //     </object>
 //
+// Lists repeat the field for each item:
+//
+//     <object>
+//       <tags>first</tags>
+//       <tags>second</tags>
+//     </object>
+//
+// Text is escaped, so values can contain characters such as < and &.
+//
 // All values are treated as strings unless a 'type' attribute is applied to the field.
 // Acceptable types values are:
 //
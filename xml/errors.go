@@ -20,3 +20,14 @@ func (e *InvalidUnmarshalError) Error() string {
 	}
 	return "codecs: xml: Unmarshal(nil " + e.Type.String() + ")"
 }
+
+// An UnmarshalTypeError describes an object passed to Unmarshal that cannot hold
+// the XML data, such as a map for a collection of objects.
+type UnmarshalTypeError struct {
+	Value reflect.Type
+	Type  reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "codecs: xml: cannot unmarshal " + e.Value.String() + " into " + e.Type.String()
+}
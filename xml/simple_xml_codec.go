@@ -1,6 +1,8 @@
 package xml
 
 import (
+	"bytes"
+	xmlEncoding "encoding/xml"
 	"fmt"
 	xml "github.com/clbanning/x2j"
 	"github.com/stretchr/codecs/constants"
@@ -25,11 +27,15 @@ var (
 	XMLElementWithTypeAttributeFormatIndented string = "<%s type=\"%s\">\n%s%s\n</%s>"
 	XMLObjectElementName                      string = "object"
 	XMLObjectsElementName                     string = "objects"
+	XMLProblemElementFormat                   string = "<problem xmlns=\"%s\">%s</problem>"
+	XMLProblemElementFormatIndented           string = "<problem xmlns=\"%s\">\n%s%s\n</problem>"
+	XMLProblemNamespace                       string = "urn:ietf:rfc:7807"
 )
 
 var validXmlContentTypes = []string{
 	"text/xml",
 	"application/xml",
+	constants.ContentTypeProblemXML,
 }
 
 // SimpleXmlCodec converts objects to and from simple XML.
@@ -73,7 +79,17 @@ func (c *SimpleXmlCodec) Unmarshal(data []byte, obj interface{}) error {
 	}
 
 	// set the obj value
-	rv.Elem().Set(reflect.ValueOf(obj))
+	if obj == nil {
+		rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+		return nil
+	}
+
+	valueOf := reflect.ValueOf(obj)
+	if !valueOf.Type().AssignableTo(rv.Elem().Type()) {
+		return &UnmarshalTypeError{valueOf.Type(), rv.Elem().Type()}
+	}
+
+	rv.Elem().Set(valueOf)
 
 	// no errors
 	return nil
@@ -117,7 +133,11 @@ func unmarshal(data string, options objx.Map) (interface{}, error) {
 	if object, ok := m[XMLObjectElementName]; ok {
 		return resolveValues(object), nil
 	} else if objects, ok := m[XMLObjectsElementName]; ok {
-		return resolveValues(objects.(map[string]interface{})[XMLObjectElementName]), nil
+		// objects without any object elements (such as <objects/>) have no values
+		if objectsMap, ok := objects.(map[string]interface{}); ok {
+			return resolveValues(objectsMap[XMLObjectElementName]), nil
+		}
+		return nil, nil
 	}
 
 	return nil, nil
@@ -163,10 +183,13 @@ func resolveValue(value interface{}) interface{} {
 
 		if explicitType, ok := valueMap["-type"]; ok {
 
+			// empty elements (such as <age type="int"/>) have no text
+			text, _ := valueMap["#text"].(string)
+
 			switch explicitType {
 			case "int":
 
-				val, err := strconv.ParseInt(valueMap["#text"].(string), 10, 64)
+				val, err := strconv.ParseInt(text, 10, 64)
 
 				if err == nil {
 					return val
@@ -174,7 +197,7 @@ func resolveValue(value interface{}) interface{} {
 
 			case "bool":
 
-				val, err := strconv.ParseBool(valueMap["#text"].(string))
+				val, err := strconv.ParseBool(text)
 
 				if err == nil {
 					return val
@@ -182,7 +205,7 @@ func resolveValue(value interface{}) interface{} {
 
 			case "float":
 
-				val, err := strconv.ParseFloat(valueMap["#text"].(string), 64)
+				val, err := strconv.ParseFloat(text, 64)
 
 				if err == nil {
 					return val
@@ -190,7 +213,7 @@ func resolveValue(value interface{}) interface{} {
 
 			case "uint":
 
-				val, err := strconv.ParseUint(valueMap["#text"].(string), 10, 64)
+				val, err := strconv.ParseUint(text, 10, 64)
 
 				if err == nil {
 					return val
@@ -198,7 +221,7 @@ func resolveValue(value interface{}) interface{} {
 
 			}
 
-			return valueMap["#text"].(string)
+			return text
 
 		} else {
 
@@ -227,23 +250,18 @@ func marshal(object interface{}, doIndent bool, indentLevel int, options objx.Ma
 	switch object.(type) {
 	case map[string]interface{}:
 
-		var objects []string
-		for k, v := range object.(map[string]interface{}) {
-
-			valueBytes, valueMarshalErr := marshal(v, doIndent, nextIndent, options)
-
-			// handle errors
-			if valueMarshalErr != nil {
-				return nil, valueMarshalErr
-			}
-
-			// add the key and value
-			el := element(k, v, string(valueBytes), doIndent, nextIndent, options)
-			objects = append(objects, el)
+		objects, err := marshalFields(object.(map[string]interface{}), doIndent, nextIndent, options)
 
+		if err != nil {
+			return nil, err
 		}
 
-		output = append(output, element(XMLObjectElementName, nil, strings.Join(objects, ""), doIndent, nextIndent, nil))
+		if indentLevel == 0 && options.Get(constants.OptionKeyMatchedType).Str() == constants.ContentTypeProblemXML {
+			// problem details (RFC 7807) use their own root element
+			output = append(output, problemElement(strings.Join(objects, ""), doIndent, nextIndent))
+		} else {
+			output = append(output, element(XMLObjectElementName, nil, strings.Join(objects, ""), doIndent, nextIndent, nil))
+		}
 
 	case []map[string]interface{}:
 
@@ -264,14 +282,135 @@ func marshal(object interface{}, doIndent bool, indentLevel int, options objx.Ma
 		output = append(output, element(XMLObjectsElementName, nil, el, doIndent, nextIndent, nil))
 
 	default:
-		// return the value
-		output = append(output, fmt.Sprintf("%v", object))
+
+		rv := reflect.ValueOf(object)
+
+		switch {
+		case object == nil:
+			// nil values have no text
+		case rv.Kind() == reflect.Map:
+			return marshal(stringKeyedMap(rv), doIndent, indentLevel, options)
+		case isList(rv):
+
+			var objects []string
+			for _, item := range listItems(rv) {
+
+				valueBytes, err := marshal(item, doIndent, nextIndent, options)
+
+				if err != nil {
+					return nil, err
+				}
+
+				// maps are already object elements
+				if item != nil && reflect.ValueOf(item).Kind() == reflect.Map {
+					objects = append(objects, string(valueBytes))
+				} else {
+					objects = append(objects, element(XMLObjectElementName, item, string(valueBytes), doIndent, nextIndent, options))
+				}
+
+			}
+
+			output = append(output, element(XMLObjectsElementName, nil, strings.Join(objects, ""), doIndent, nextIndent, nil))
+
+		case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+			output = append(output, escapeText(string(rv.Bytes())))
+		default:
+			// return the value
+			output = append(output, escapeText(fmt.Sprintf("%v", object)))
+		}
+
 	}
 
 	return []byte(strings.Join(output, "")), nil
 
 }
 
+// marshalFields generates an element for each value of the map.  Lists repeat
+// the element for each item, and the fields of maps are elements inside it.
+func marshalFields(object map[string]interface{}, doIndent bool, indentLevel int, options objx.Map) ([]string, error) {
+
+	var fields []string
+	for k, v := range object {
+
+		// empty lists have an empty element
+		items := []interface{}{v}
+		if rv := reflect.ValueOf(v); isList(rv) && rv.Len() > 0 {
+			items = listItems(rv)
+		} else if isList(rv) {
+			items = []interface{}{nil}
+		}
+
+		for _, item := range items {
+
+			var valueString string
+
+			if rv := reflect.ValueOf(item); item != nil && rv.Kind() == reflect.Map {
+
+				children, err := marshalFields(stringKeyedMap(rv), doIndent, indentLevel+1, options)
+				if err != nil {
+					return nil, err
+				}
+				valueString = strings.Join(children, "")
+
+			} else {
+
+				valueBytes, err := marshal(item, doIndent, indentLevel, options)
+				if err != nil {
+					return nil, err
+				}
+				valueString = string(valueBytes)
+
+			}
+
+			// add the key and value
+			fields = append(fields, element(k, item, valueString, doIndent, indentLevel, options))
+
+		}
+
+	}
+
+	return fields, nil
+}
+
+// isList gets whether the value is a slice or array, other than a []byte, whose
+// items are marshalled as repeated elements.
+func isList(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+	return false
+}
+
+// listItems gets the items of a slice or array.
+func listItems(rv reflect.Value) []interface{} {
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items
+}
+
+// stringKeyedMap converts any map into a map[string]interface{}, so that its
+// values are marshalled as elements.
+func stringKeyedMap(rv reflect.Value) map[string]interface{} {
+	object := make(map[string]interface{}, rv.Len())
+	for _, key := range rv.MapKeys() {
+		object[fmt.Sprint(key.Interface())] = rv.MapIndex(key).Interface()
+	}
+	return object
+}
+
+// escapeText escapes the characters of the text that cannot appear in the
+// content of an element, such as < and &.
+func escapeText(text string) string {
+	var buffer bytes.Buffer
+	xmlEncoding.EscapeText(&buffer, []byte(text))
+	return buffer.String()
+}
+
 func element(k string, v interface{}, vString string, doIndent bool, indentLevel int, options objx.Map) string {
 
 	var typeString string
@@ -298,6 +437,19 @@ func element(k string, v interface{}, vString string, doIndent bool, indentLevel
 
 }
 
+// problemElement generates the root element for an RFC 7807 problem details
+// document, containing the specified content.
+func problemElement(vString string, doIndent bool, indentLevel int) string {
+
+	if doIndent {
+		indent := strings.Repeat(Indentation, indentLevel)
+		return fmt.Sprintf(XMLProblemElementFormatIndented, XMLProblemNamespace, indent, vString)
+	}
+
+	return fmt.Sprintf(XMLProblemElementFormat, XMLProblemNamespace, vString)
+
+}
+
 // getTypeString gets a simple string describing the type of the object
 // passed in.
 //
@@ -1,10 +1,12 @@
 package xml
 
 import (
+	xmlEncoding "encoding/xml"
 	"github.com/stretchr/codecs"
 	"github.com/stretchr/codecs/constants"
 	"github.com/stretchr/objx"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
@@ -197,3 +199,127 @@ func TestGetTypeString(t *testing.T) {
 	assert.Equal(t, "float", getTypeString(10.23294))
 
 }
+
+func TestMarshal_problemDetails(t *testing.T) {
+
+	obj := map[string]interface{}{"status": 404}
+	options := map[string]interface{}{constants.OptionKeyMatchedType: constants.ContentTypeProblemXML}
+
+	bytes, err := xmlCodec.Marshal(obj, options)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<?xml version=\"1.0\"?><problem xmlns=\"urn:ietf:rfc:7807\">\n  <status>\n  404\n</status>\n</problem>", string(bytes))
+	}
+
+}
+
+func TestMarshal_problemDetailsEscaped(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"status": 400,
+		"detail": "invalid character '<' looking for beginning of value & more",
+		"errors": []interface{}{map[string]interface{}{"field": "name"}, map[string]string{"field": "age"}},
+	}
+	options := map[string]interface{}{constants.OptionKeyMatchedType: constants.ContentTypeProblemXML}
+
+	bytes, err := xmlCodec.Marshal(obj, options)
+
+	if assert.NoError(t, err) {
+
+		var problem struct {
+			XMLName xmlEncoding.Name `xml:"urn:ietf:rfc:7807 problem"`
+			Status  string           `xml:"status"`
+			Detail  string           `xml:"detail"`
+			Errors  []struct {
+				Field string `xml:"field"`
+			} `xml:"errors"`
+		}
+
+		if assert.NoError(t, xmlEncoding.Unmarshal(bytes, &problem), string(bytes)) {
+			assert.Equal(t, "400", strings.TrimSpace(problem.Status))
+			assert.Equal(t, "invalid character '<' looking for beginning of value & more", strings.TrimSpace(problem.Detail))
+			if assert.Equal(t, 2, len(problem.Errors)) {
+				assert.Equal(t, "name", strings.TrimSpace(problem.Errors[0].Field))
+				assert.Equal(t, "age", strings.TrimSpace(problem.Errors[1].Field))
+			}
+		}
+
+	}
+
+}
+
+func TestMarshalAndUnmarshal_ListsAndNestedMaps(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"tags":    []string{"a", "<b>"},
+		"address": map[string]interface{}{"city": "Boulder & Denver"},
+		"pets":    []interface{}{map[string]interface{}{"name": "Dog"}, map[string]interface{}{"name": "Cat"}},
+		"nothing": nil,
+	}
+
+	bytes, err := xmlCodec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+
+		// the output is well formed
+		var document struct{}
+		assert.NoError(t, xmlEncoding.Unmarshal(bytes, &document), string(bytes))
+
+		var newObj map[string]interface{}
+		if assert.NoError(t, xmlCodec.Unmarshal(bytes, &newObj)) {
+			assert.Equal(t, map[string]interface{}{
+				"tags":    []interface{}{"a", "<b>"},
+				"address": map[string]interface{}{"city": "Boulder & Denver"},
+				"pets":    []interface{}{map[string]interface{}{"name": "Dog"}, map[string]interface{}{"name": "Cat"}},
+				"nothing": "",
+			}, newObj)
+		}
+
+	}
+
+}
+
+func TestUnmarshal_EmptyElements(t *testing.T) {
+
+	var obj interface{}
+
+	if assert.NoError(t, xmlCodec.Unmarshal([]byte(`<object><age type="int"/><name type="string"></name></object>`), &obj)) {
+		assert.Equal(t, map[string]interface{}{"age": "", "name": ""}, obj)
+	}
+
+	if assert.NoError(t, xmlCodec.Unmarshal([]byte("<objects>none</objects>"), &obj)) {
+		assert.Nil(t, obj)
+	}
+
+	obj = "previous"
+	if assert.NoError(t, xmlCodec.Unmarshal([]byte("<other>value</other>"), &obj)) {
+		assert.Nil(t, obj)
+	}
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var object map[string]interface{}
+	assert.IsType(t, &InvalidUnmarshalError{}, xmlCodec.Unmarshal([]byte("<object></object>"), object))
+
+	// a collection cannot go into a map
+	err := xmlCodec.Unmarshal([]byte("<objects><object><a>1</a></object><object><a>2</a></object></objects>"), &object)
+	assert.IsType(t, &UnmarshalTypeError{}, err)
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	f.Add([]byte(`<object><name>Mat</name><age type="int">30</age></object>`))
+	f.Add([]byte(`<objects><object><name>Mat</name></object><object><name>Tyler</name></object></objects>`))
+	f.Add([]byte(`<object><address><city>London</city></address><age type="int"/></object>`))
+	f.Add([]byte(`<objects>none</objects>`))
+	f.Add([]byte(`<?xml version="1.0"?><object`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		xmlCodec.Unmarshal(data, &object)
+	})
+
+}
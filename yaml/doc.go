@@ -0,0 +1,14 @@
+// A codec for handling YAML encoding and decoding.
+//
+// Decoded maps always have string keys, so objects decoded from YAML are the same
+// shape as those decoded by the other codecs.
+//
+// # Multiple documents
+//
+// A YAML stream may contain more than one document, separated by "---".  When
+// unmarshalling a stream containing multiple documents into an interface{} or a
+// slice, each document becomes an item in the slice.
+//
+// When marshalling a slice, the OptionMultipleDocuments option causes each item to
+// be written as a separate document.
+package yaml
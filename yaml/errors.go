@@ -0,0 +1,27 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+)
+
+// An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
+// (The argument to Unmarshal must be a non-nil pointer.)
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "codecs: yaml: Unmarshal(nil)"
+	}
+
+	if e.Type.Kind() != reflect.Ptr {
+		return "codecs: yaml: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "codecs: yaml: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// ErrorMultipleDocuments is the error for when a stream containing multiple documents
+// is unmarshalled into an object that cannot hold more than one.
+var ErrorMultipleDocuments = errors.New("codecs: yaml: multiple documents can only be unmarshalled into an interface{} or a slice")
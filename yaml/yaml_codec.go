@@ -0,0 +1,221 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/codecs/constants"
+	yamlEncoding "gopkg.in/yaml.v2"
+	"io"
+	"reflect"
+)
+
+const (
+	// OptionMultipleDocuments is the option key that, when true, causes Marshal to
+	// write each item of a slice as a separate document in the stream.
+	OptionMultipleDocuments string = "options.yaml.documents"
+)
+
+var validYamlContentTypes = []string{
+	"application/yaml",
+	"application/x-yaml",
+	"text/yaml",
+	"text/x-yaml",
+}
+
+var validYamlFileExtensions = []string{
+	constants.FileExtensionYAML,
+	constants.FileExtensionYML,
+}
+
+// YamlCodec converts objects to and from YAML.
+type YamlCodec struct{}
+
+// Marshal converts an object to YAML.
+func (c *YamlCodec) Marshal(object interface{}, options map[string]interface{}) ([]byte, error) {
+
+	documents := []interface{}{object}
+
+	if multiple, _ := options[OptionMultipleDocuments].(bool); multiple {
+		if objectValue := reflect.ValueOf(object); objectValue.Kind() == reflect.Slice || objectValue.Kind() == reflect.Array {
+			documents = make([]interface{}, objectValue.Len())
+			for index := range documents {
+				documents[index] = objectValue.Index(index).Interface()
+			}
+		}
+	}
+
+	byteBuffer := new(bytes.Buffer)
+	encoder := yamlEncoding.NewEncoder(byteBuffer)
+
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return byteBuffer.Bytes(), nil
+}
+
+// Unmarshal converts YAML into an object.
+//
+// If the data contains multiple documents, obj must be a pointer to an interface{}
+// or a slice, and each document becomes an item.
+func (c *YamlCodec) Unmarshal(data []byte, obj interface{}) error {
+
+	// check the value
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(obj)}
+	}
+
+	count, err := countDocuments(data)
+
+	if err != nil {
+		return err
+	}
+
+	target := rv.Elem()
+
+	if count <= 1 {
+
+		if err := yamlEncoding.Unmarshal(data, obj); err != nil {
+			return err
+		}
+
+	} else {
+
+		var documents reflect.Value
+
+		switch target.Kind() {
+		case reflect.Interface:
+			documents = reflect.ValueOf(make([]interface{}, count))
+		case reflect.Slice:
+			documents = reflect.MakeSlice(target.Type(), count, count)
+		default:
+			return ErrorMultipleDocuments
+		}
+
+		decoder := yamlEncoding.NewDecoder(bytes.NewReader(data))
+
+		for index := 0; index < count; index++ {
+			if err := decoder.Decode(documents.Index(index).Addr().Interface()); err != nil {
+				return err
+			}
+		}
+
+		target.Set(documents)
+
+	}
+
+	normaliseTarget(target)
+
+	return nil
+}
+
+// ContentType returns the content type for this codec.
+func (c *YamlCodec) ContentType() string {
+	return constants.ContentTypeYAML
+}
+
+// FileExtension returns the file extension for this codec.
+func (c *YamlCodec) FileExtension() string {
+	return constants.FileExtensionYAML
+}
+
+// FileExtensions returns all of the file extensions by which this codec is represented.
+func (c *YamlCodec) FileExtensions() []string {
+	return validYamlFileExtensions
+}
+
+// CanMarshalWithCallback returns whether this codec is capable of marshalling a response containing a callback.
+func (c *YamlCodec) CanMarshalWithCallback() bool {
+	return false
+}
+
+func (c *YamlCodec) ContentTypeSupported(contentType string) bool {
+	for _, supportedType := range validYamlContentTypes {
+		if supportedType == contentType {
+			return true
+		}
+	}
+	return contentType == c.ContentType()
+}
+
+// countDocuments counts the documents in the YAML stream.
+func countDocuments(data []byte) (int, error) {
+
+	decoder := yamlEncoding.NewDecoder(bytes.NewReader(data))
+
+	for count := 0; ; count++ {
+		var document interface{}
+		if err := decoder.Decode(&document); err == io.EOF {
+			return count, nil
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+}
+
+// normaliseTarget replaces any map[interface{}]interface{} values held in
+// interface{} values within the target with map[string]interface{}.
+func normaliseTarget(target reflect.Value) {
+
+	switch target.Kind() {
+	case reflect.Interface:
+		if !target.IsNil() && target.CanSet() {
+			target.Set(reflect.ValueOf(normalise(target.Interface())))
+		}
+	case reflect.Ptr:
+		if !target.IsNil() {
+			normaliseTarget(target.Elem())
+		}
+	case reflect.Map:
+		if target.Type().Elem().Kind() == reflect.Interface {
+			for _, key := range target.MapKeys() {
+				if value := target.MapIndex(key); !value.IsNil() {
+					target.SetMapIndex(key, reflect.ValueOf(normalise(value.Interface())))
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for index := 0; index < target.Len(); index++ {
+			normaliseTarget(target.Index(index))
+		}
+	case reflect.Struct:
+		for index := 0; index < target.NumField(); index++ {
+			if field := target.Field(index); field.CanSet() {
+				normaliseTarget(field)
+			}
+		}
+	}
+
+}
+
+// normalise converts map[interface{}]interface{} values (as decoded by the yaml
+// package) into map[string]interface{}, recursively.
+func normalise(object interface{}) interface{} {
+
+	switch typedObject := object.(type) {
+	case map[interface{}]interface{}:
+		normalised := make(map[string]interface{}, len(typedObject))
+		for k, v := range typedObject {
+			normalised[fmt.Sprintf("%v", k)] = normalise(v)
+		}
+		return normalised
+	case map[string]interface{}:
+		for k, v := range typedObject {
+			typedObject[k] = normalise(v)
+		}
+	case []interface{}:
+		for i, v := range typedObject {
+			typedObject[i] = normalise(v)
+		}
+	}
+
+	return object
+}
@@ -0,0 +1,186 @@
+package yaml
+
+import (
+	"github.com/stretchr/codecs"
+	"github.com/stretchr/codecs/constants"
+	"github.com/stretchr/objx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var codec YamlCodec
+
+func TestInterface(t *testing.T) {
+
+	assert.Implements(t, (*codecs.Codec)(nil), new(YamlCodec), "YamlCodec")
+	assert.Implements(t, (*codecs.ContentTypeMatcherCodec)(nil), new(YamlCodec), "YamlCodec")
+	assert.Implements(t, (*codecs.FileExtensionsCodec)(nil), new(YamlCodec), "YamlCodec")
+
+}
+
+func TestMarshal(t *testing.T) {
+
+	obj := map[string]interface{}{
+		"name": "Mat",
+		"age":  30,
+		"address": map[string]interface{}{
+			"city": "Boulder",
+		},
+		"animals": []interface{}{"Dog", "Cat"},
+	}
+
+	yamlData, err := codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "address:\n  city: Boulder\nage: 30\nanimals:\n- Dog\n- Cat\nname: Mat\n", string(yamlData))
+	}
+
+}
+
+func TestMarshal_ObjxMap(t *testing.T) {
+
+	yamlData, err := codec.Marshal(objx.MSI("name", "Mat"), nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "name: Mat\n", string(yamlData))
+	}
+
+}
+
+func TestMarshal_MultipleDocuments(t *testing.T) {
+
+	obj := []interface{}{map[string]interface{}{"name": "Mat"}, map[string]interface{}{"name": "Tyler"}}
+
+	yamlData, err := codec.Marshal(obj, map[string]interface{}{OptionMultipleDocuments: true})
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "name: Mat\n---\nname: Tyler\n", string(yamlData))
+	}
+
+	yamlData, err = codec.Marshal(obj, nil)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, "- name: Mat\n- name: Tyler\n", string(yamlData))
+	}
+
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	yamlString := "name: Mat\naddress:\n  city: Boulder\n  coords: [{lat: 40}]\n1: one\n"
+	var object interface{}
+
+	if assert.NoError(t, codec.Unmarshal([]byte(yamlString), &object)) {
+		assert.Equal(t, map[string]interface{}{
+			"name": "Mat",
+			"address": map[string]interface{}{
+				"city":   "Boulder",
+				"coords": []interface{}{map[string]interface{}{"lat": 40}},
+			},
+			"1": "one",
+		}, object)
+	}
+
+}
+
+func TestUnmarshal_Map(t *testing.T) {
+
+	yamlString := "name: Mat\naddress:\n  city: Boulder\n"
+	var object map[string]interface{}
+
+	if assert.NoError(t, codec.Unmarshal([]byte(yamlString), &object)) {
+		assert.Equal(t, "Mat", object["name"])
+		assert.Equal(t, map[string]interface{}{"city": "Boulder"}, object["address"])
+	}
+
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+
+	var object struct {
+		Name string
+		Meta map[string]interface{}
+	}
+
+	if assert.NoError(t, codec.Unmarshal([]byte("name: Mat\nmeta:\n  level:\n    deep: value\n"), &object)) {
+		assert.Equal(t, "Mat", object.Name)
+		assert.Equal(t, map[string]interface{}{"deep": "value"}, object.Meta["level"])
+	}
+
+}
+
+func TestUnmarshal_MultipleDocuments(t *testing.T) {
+
+	yamlString := "name: Mat\n---\nname: Tyler\n"
+
+	var object interface{}
+
+	if assert.NoError(t, codec.Unmarshal([]byte(yamlString), &object)) {
+		assert.Equal(t, []interface{}{map[string]interface{}{"name": "Mat"}, map[string]interface{}{"name": "Tyler"}}, object)
+	}
+
+	var people []struct{ Name string }
+
+	if assert.NoError(t, codec.Unmarshal([]byte(yamlString), &people)) && assert.Equal(t, 2, len(people)) {
+		assert.Equal(t, "Mat", people[0].Name)
+		assert.Equal(t, "Tyler", people[1].Name)
+	}
+
+	var person struct{ Name string }
+
+	assert.Equal(t, ErrorMultipleDocuments, codec.Unmarshal([]byte(yamlString), &person))
+
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+
+	var object interface{}
+
+	assert.IsType(t, &InvalidUnmarshalError{}, codec.Unmarshal([]byte("name: Mat"), object))
+	assert.Error(t, codec.Unmarshal([]byte("name: [Mat"), &object))
+
+}
+
+func TestResponseContentType(t *testing.T) {
+
+	assert.Equal(t, constants.ContentTypeYAML, codec.ContentType())
+
+}
+
+func TestContentTypeSupported(t *testing.T) {
+
+	for _, contentType := range []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"} {
+		assert.True(t, codec.ContentTypeSupported(contentType), contentType)
+	}
+	assert.False(t, codec.ContentTypeSupported(constants.ContentTypeJSON))
+
+}
+
+func TestFileExtension(t *testing.T) {
+
+	assert.Equal(t, constants.FileExtensionYAML, codec.FileExtension())
+	assert.Equal(t, []string{".yaml", ".yml"}, codec.FileExtensions())
+
+}
+
+func TestCanMarshalWithCallback(t *testing.T) {
+
+	assert.False(t, codec.CanMarshalWithCallback())
+
+}
+
+func FuzzUnmarshal(f *testing.F) {
+
+	codec := new(YamlCodec)
+
+	f.Add([]byte("name: Mat\nage: 30\ntags:\n  - a\n  - b\n"))
+	f.Add([]byte("a: &a [*a]\n"))
+	f.Add([]byte("? [1, 2]\n: complex key\n"))
+	f.Add([]byte("- {1: one, true: yes}\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var object interface{}
+		codec.Unmarshal(data, &object)
+	})
+
+}